@@ -8,27 +8,104 @@ import (
 
 // Export models
 type (
-	Address      = models.Address
-	Customer     = models.Customer
-	Item         = models.Item
-	Menu         = models.Menu
-	NearbyStores = models.NearbyStores
-	Order        = models.Order
-	Payment      = models.Payment
-	Store        = models.Store
-	Tracking     = models.Tracking
+	Address       = models.Address
+	CardBrand     = models.CardBrand
+	Coupon        = models.Coupon
+	Customer      = models.Customer
+	Deal          = models.Deal
+	DealFinder    = models.DealFinder
+	Item          = models.Item
+	Market        = utils.Market
+	Menu          = models.Menu
+	Money         = models.Money
+	NearbyStores  = models.NearbyStores
+	Order         = models.Order
+	OrderRef      = models.OrderRef
+	Payment       = models.Payment
+	Product       = models.Product
+	Store         = models.Store
+	TipOption     = models.TipOption
+	TrackerURLs   = utils.TrackerURLs
+	Tracking      = models.Tracking
+	TrackingEvent = models.TrackingEvent
+	URLConfig     = utils.URLConfig
+
+	CookingInstruction = models.CookingInstruction
+	CouponProductGroup = models.CouponProductGroup
+	CouponShortfall    = models.CouponShortfall
+	ImageFetcher       = models.ImageFetcher
+	ItemBuilder        = models.ItemBuilder
+	ItemChange         = models.ItemChange
+	ItemNutrition      = models.ItemNutrition
+	MenuDiff           = models.MenuDiff
+	MenuComparer       = models.MenuComparer
+	MenuExport         = models.MenuExport
+	MenuItemChange     = models.MenuItemChange
+	MenuPriceChange    = models.MenuPriceChange
+	MenuProductChange  = models.MenuProductChange
+	MenuQuery          = models.MenuQuery
+	MenuSearch         = models.MenuSearch
+	MenuSearchResult   = models.MenuSearchResult
+	MenuTable          = models.MenuTable
+	MenuToppingChange  = models.MenuToppingChange
+	OrderAmbiguity     = models.OrderAmbiguity
+	OrderParser        = models.OrderParser
+	ParsedOrder        = models.ParsedOrder
+	PriceComparison    = models.PriceComparison
+	Nutrition          = models.Nutrition
+	OrderNutrition     = models.OrderNutrition
+	ProductImage       = models.ProductImage
+	StoreFailure       = models.StoreFailure
+	UpsellSuggestion   = models.UpsellSuggestion
+	VariantPrices      = models.VariantPrices
+
+	WebhookDelivery   = models.WebhookDelivery
+	WebhookDispatcher = models.WebhookDispatcher
+	WebhookEvent      = models.WebhookEvent
+
+	CookingInstructionGroup = models.CookingInstructionGroup
 )
 
 // Export constructors
 var (
-	NewAddress      = models.NewAddress
-	NewCustomer     = models.NewCustomer
-	NewItem         = models.NewItem
-	NewNearbyStores = models.NewNearbyStores
-	NewOrder        = models.NewOrder
-	NewPayment      = models.NewPayment
-	NewStore        = models.NewStore
-	NewTracking     = models.NewTracking
+	DiffMenus            = models.DiffMenus
+	NewAddress           = models.NewAddress
+	NewCashPayment       = models.NewCashPayment
+	NewCoupon            = models.NewCoupon
+	NewCustomer          = models.NewCustomer
+	NewDealFinder        = models.NewDealFinder
+	NewDoorCreditPayment = models.NewDoorCreditPayment
+	NewGiftCardPayment   = models.NewGiftCardPayment
+	NewImageFetcher      = models.NewImageFetcher
+	NewItem              = models.NewItem
+	NewMenuComparer      = models.NewMenuComparer
+	NewMenuExport        = models.NewMenuExport
+	NewMenuSearch        = models.NewMenuSearch
+	NewMoney             = models.NewMoney
+	NewNearbyStores      = models.NewNearbyStores
+	NewOrder             = models.NewOrder
+	NewOrderParser       = models.NewOrderParser
+	NewPayment           = models.NewPayment
+	NewProduct           = models.NewProduct
+	NewSavedCardPayment  = models.NewSavedCardPayment
+	NewStore             = models.NewStore
+	NewTokenizedPayment  = models.NewTokenizedPayment
+	NewTracking          = models.NewTracking
+	NewWebhookDispatcher = models.NewWebhookDispatcher
+
+	MoneyFromFloat = models.MoneyFromFloat
+	ParseMoney     = models.ParseMoney
+
+	// Payment tokenization
+	GetPaymentClientToken = models.GetPaymentClientToken
+
+	// Card validation
+	DetectCardBrand     = models.DetectCardBrand
+	LuhnValid           = models.LuhnValid
+	CardBrandVisa       = models.CardBrandVisa
+	CardBrandMastercard = models.CardBrandMastercard
+	CardBrandAmex       = models.CardBrandAmex
+	CardBrandDiscover   = models.CardBrandDiscover
 )
 
 // Export utility functions and values
@@ -38,11 +115,29 @@ var (
 	USA              = utils.USA
 	Canada           = utils.Canada
 	UseInternational = utils.UseInternational
+	UseLanguage      = utils.UseLanguage
+	CurrentLanguage  = utils.CurrentLanguage
+
+	// Market registry
+	USMarket       = utils.USMarket
+	CanadaMarket   = utils.CanadaMarket
+	RegisterMarket = utils.RegisterMarket
+	LookupMarket   = utils.LookupMarket
+	Markets        = utils.Markets
+	UseMarket      = utils.UseMarket
+	CurrentMarket  = utils.CurrentMarket
+
+	// Redaction
+	ShowSensitiveData = utils.ShowSensitiveData
 
 	// HTTP utilities
 	Get         = utils.Get
 	Post        = utils.Post
 	GetTracking = utils.GetTracking
+
+	// Webhook signatures
+	SignWebhookPayload     = models.SignWebhookPayload
+	VerifyWebhookSignature = models.VerifyWebhookSignature
 )
 
 // Export error constructors
@@ -55,4 +150,55 @@ var (
 	NewDominosDateError       = utils.NewDominosDateError
 	NewDominosStoreError      = utils.NewDominosStoreError
 	NewDominosProductsError   = utils.NewDominosProductsError
+	NewDominosMarketError     = utils.NewDominosMarketError
+	NewDominosCurrencyError   = utils.NewDominosCurrencyError
+	NewDominosPaymentError    = utils.NewDominosPaymentError
+	NewDominosCardError       = utils.NewDominosCardError
+	NewDominosCouponError     = utils.NewDominosCouponError
+)
+
+// Export payment types
+const (
+	PaymentTypeCreditCard = models.PaymentTypeCreditCard
+	PaymentTypeCash       = models.PaymentTypeCash
+	PaymentTypeGiftCard   = models.PaymentTypeGiftCard
+	PaymentTypeDoorCredit = models.PaymentTypeDoorCredit
+)
+
+// Export card validation failures
+const (
+	CardNumberInvalid       = models.CardNumberInvalid
+	CardBrandUnsupported    = models.CardBrandUnsupported
+	CardExpirationInvalid   = models.CardExpirationInvalid
+	CardExpired             = models.CardExpired
+	CardSecurityCodeInvalid = models.CardSecurityCodeInvalid
+)
+
+// Export tracking strategies
+const (
+	TrackerPresentationService = utils.TrackerPresentationService
+	OrderStorageTracker        = utils.OrderStorageTracker
+)
+
+// Export dietary tags
+const (
+	DietaryVegetarian = models.DietaryVegetarian
+	DietaryVegan      = models.DietaryVegan
+	DietaryGlutenFree = models.DietaryGlutenFree
+)
+
+// Export topping portions
+const (
+	PortionWhole = models.PortionWhole
+	PortionLeft  = models.PortionLeft
+	PortionRight = models.PortionRight
+)
+
+// Export cooking instruction codes
+const (
+	CookingPieCut     = models.CookingPieCut
+	CookingSquareCut  = models.CookingSquareCut
+	CookingUncut      = models.CookingUncut
+	CookingWellDone   = models.CookingWellDone
+	CookingNormalBake = models.CookingNormalBake
 )
@@ -98,3 +98,13 @@ func (a *Address) GetDefaultLineTwo() string {
 	}
 	return ""
 }
+
+// GetFormatted returns the address as a map with PascalCase keys
+func (a *Address) GetFormatted() map[string]interface{} {
+	return getFormatted(a)
+}
+
+// SetFormatted updates the address from a map with keys in any format
+func (a *Address) SetFormatted(data map[string]interface{}) {
+	setFormatted(a, data)
+}
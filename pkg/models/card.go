@@ -0,0 +1,178 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// Card validation failures, reported as the Details of a DominosCardError
+const (
+	CardNumberInvalid       = "card number is invalid"
+	CardBrandUnsupported    = "card brand is not supported"
+	CardExpirationInvalid   = "expiration must be MM/YY"
+	CardExpired             = "card is expired"
+	CardSecurityCodeInvalid = "security code has the wrong length for this card"
+)
+
+// CardBrand describes a credit card brand accepted by Domino's
+type CardBrand struct {
+	// CardType is the value Domino's expects in a payment's CardType
+	CardType string
+	// Name matches the store profile's AcceptableCreditCards
+	Name               string
+	Lengths            []int
+	SecurityCodeLength int
+	prefixes           [][2]int // inclusive ranges of leading digits
+}
+
+// Card brands recognized by DetectCardBrand
+var (
+	CardBrandVisa = &CardBrand{
+		CardType:           "VISA",
+		Name:               "Visa",
+		Lengths:            []int{13, 16, 19},
+		SecurityCodeLength: 3,
+		prefixes:           [][2]int{{4, 4}},
+	}
+	CardBrandMastercard = &CardBrand{
+		CardType:           "MASTERCARD",
+		Name:               "Mastercard",
+		Lengths:            []int{16},
+		SecurityCodeLength: 3,
+		prefixes:           [][2]int{{51, 55}, {2221, 2720}},
+	}
+	CardBrandAmex = &CardBrand{
+		CardType:           "AMEX",
+		Name:               "American Express",
+		Lengths:            []int{15},
+		SecurityCodeLength: 4,
+		prefixes:           [][2]int{{34, 34}, {37, 37}},
+	}
+	CardBrandDiscover = &CardBrand{
+		CardType:           "DISCOVER",
+		Name:               "Discover",
+		Lengths:            []int{16, 17, 18, 19},
+		SecurityCodeLength: 3,
+		prefixes:           [][2]int{{6011, 6011}, {622126, 622925}, {644, 649}, {65, 65}},
+	}
+
+	cardBrands = []*CardBrand{CardBrandVisa, CardBrandMastercard, CardBrandAmex, CardBrandDiscover}
+)
+
+// DetectCardBrand returns the brand of a card number, or nil if it isn't recognized
+func DetectCardBrand(number string) *CardBrand {
+	for _, brand := range cardBrands {
+		for _, prefix := range brand.prefixes {
+			digits := len(strconv.Itoa(prefix[0]))
+			if len(number) < digits {
+				continue
+			}
+
+			leading, err := strconv.Atoi(number[:digits])
+			if err != nil {
+				return nil
+			}
+			if leading >= prefix[0] && leading <= prefix[1] {
+				return brand
+			}
+		}
+	}
+	return nil
+}
+
+// LuhnValid reports whether a string of digits passes the Luhn checksum
+func LuhnValid(number string) bool {
+	if number == "" {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+		if digit < 0 || digit > 9 {
+			return false
+		}
+
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+// parseCardExpiration parses an MMYY or MMYYYY expiration (slashes already removed)
+// into the first moment after the card expires
+func parseCardExpiration(expiration string) (time.Time, bool) {
+	if len(expiration) != 4 && len(expiration) != 6 {
+		return time.Time{}, false
+	}
+
+	month, err := strconv.Atoi(expiration[:2])
+	if err != nil || month < 1 || month > 12 {
+		return time.Time{}, false
+	}
+
+	year, err := strconv.Atoi(expiration[2:])
+	if err != nil {
+		return time.Time{}, false
+	}
+	if len(expiration) == 4 {
+		year += 2000
+	}
+
+	// Cards are valid through the last day of their expiration month
+	return time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, time.Local), true
+}
+
+// ValidateCard checks a credit card payment's number, brand, expiration and
+// security code as of now, and sets CardType to the detected brand
+func (p *Payment) ValidateCard(now time.Time) error {
+	number := strings.NewReplacer("-", "", " ", "").Replace(p.Number)
+	if !LuhnValid(number) {
+		return utils.NewDominosCardError(CardNumberInvalid)
+	}
+
+	brand := DetectCardBrand(number)
+	if brand == nil {
+		return utils.NewDominosCardError(CardBrandUnsupported)
+	}
+
+	validLength := false
+	for _, length := range brand.Lengths {
+		validLength = validLength || len(number) == length
+	}
+	if !validLength {
+		return utils.NewDominosCardError(CardNumberInvalid)
+	}
+
+	expires, ok := parseCardExpiration(strings.ReplaceAll(p.Expiration, "/", ""))
+	if !ok {
+		return utils.NewDominosCardError(CardExpirationInvalid)
+	}
+	if !now.Before(expires) {
+		return utils.NewDominosCardError(CardExpired)
+	}
+
+	if len(p.SecurityCode) != brand.SecurityCodeLength {
+		return utils.NewDominosCardError(CardSecurityCodeInvalid)
+	}
+	for _, char := range p.SecurityCode {
+		if char < '0' || char > '9' {
+			return utils.NewDominosCardError(CardSecurityCodeInvalid)
+		}
+	}
+
+	p.CardType = brand.CardType
+
+	return nil
+}
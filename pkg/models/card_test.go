@@ -0,0 +1,99 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+func TestLuhnValid(t *testing.T) {
+	tests := []struct {
+		number string
+		want   bool
+	}{
+		{"4111111111111111", true},
+		{"4111111111111112", false},
+		{"5555555555554444", true},
+		{"378282246310005", true},
+		{"6011111111111117", true},
+		{"0", true},
+		{"", false},
+		{"4111-1111-1111-1111", false},
+		{"41111111111111a1", false},
+	}
+	for _, test := range tests {
+		if got := LuhnValid(test.number); got != test.want {
+			t.Errorf("LuhnValid(%q) = %v, want %v", test.number, got, test.want)
+		}
+	}
+}
+
+func TestDetectCardBrand(t *testing.T) {
+	tests := []struct {
+		number string
+		want   *CardBrand
+	}{
+		{"4111111111111111", CardBrandVisa},
+		{"5105105105105100", CardBrandMastercard},
+		{"2221000000000009", CardBrandMastercard},
+		{"2720990000000007", CardBrandMastercard},
+		{"2721000000000000", nil},
+		{"378282246310005", CardBrandAmex},
+		{"341111111111111", CardBrandAmex},
+		{"6011000990139424", CardBrandDiscover},
+		{"6221260000000000", CardBrandDiscover},
+		{"6445644564456445", CardBrandDiscover},
+		{"6500000000000002", CardBrandDiscover},
+		{"3530111333300000", nil},
+		{"", nil},
+		{"x111111111111111", nil},
+	}
+	for _, test := range tests {
+		if got := DetectCardBrand(test.number); got != test.want {
+			t.Errorf("DetectCardBrand(%q) = %v, want %v", test.number, got, test.want)
+		}
+	}
+}
+
+func TestValidateCard(t *testing.T) {
+	now := time.Date(2026, time.March, 15, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		number     string
+		expiration string
+		cvv        string
+		want       string
+	}{
+		{"4111 1111 1111 1111", "03/26", "123", ""},
+		{"4111-1111-1111-1111", "122030", "123", ""},
+		{"378282246310005", "0127", "1234", ""},
+		{"4111111111111112", "0127", "123", CardNumberInvalid},
+		{"3530111333300000", "0127", "123", CardBrandUnsupported},
+		{"41111111111111113", "0127", "123", CardNumberInvalid},
+		{"4111111111111111", "1327", "123", CardExpirationInvalid},
+		{"4111111111111111", "127", "123", CardExpirationInvalid},
+		{"4111111111111111", "0226", "123", CardExpired},
+		{"378282246310005", "0127", "123", CardSecurityCodeInvalid},
+		{"4111111111111111", "0127", "12a", CardSecurityCodeInvalid},
+	}
+	for _, test := range tests {
+		payment := &Payment{Number: test.number, Expiration: test.expiration, SecurityCode: test.cvv}
+		err := payment.ValidateCard(now)
+
+		if test.want == "" {
+			if err != nil {
+				t.Errorf("ValidateCard(%s, %s, %s) failed: %v", test.number, test.expiration, test.cvv, err)
+			} else if payment.CardType == "" {
+				t.Errorf("ValidateCard(%s) didn't set the card type", test.number)
+			}
+			continue
+		}
+
+		var cardErr *utils.DominosCardError
+		if !errors.As(err, &cardErr) || cardErr.Details != test.want {
+			t.Errorf("ValidateCard(%s, %s, %s) = %v, want %q", test.number, test.expiration, test.cvv, err, test.want)
+		}
+	}
+}
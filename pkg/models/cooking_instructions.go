@@ -0,0 +1,291 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// Common cooking instruction codes. Menus list others, which can be found by
+// name with Menu.FindCookingInstruction.
+const (
+	CookingPieCut     = "PIECT"
+	CookingSquareCut  = "SQCT"
+	CookingUncut      = "UNCT"
+	CookingWellDone   = "WD"
+	CookingNormalBake = "RGB"
+)
+
+// CookingInstruction is a way to prepare a product, such as well done or
+// square cut, from the menu's CookingInstructions
+type CookingInstruction struct {
+	Code        string
+	Name        string
+	Description string
+	// Group is the code of the group the instruction belongs to, such as "CUT"
+	Group string
+}
+
+// CookingInstructionGroup is a set of cooking instructions, such as the ways a
+// pizza can be cut, of which an item can have at most MaxOptions
+type CookingInstructionGroup struct {
+	Code         string
+	Name         string
+	MaxOptions   int
+	Instructions []string
+	// ProductTypes the group applies to, or any if empty
+	ProductTypes []string
+}
+
+// GetCookingInstruction returns a cooking instruction by code
+func (m *Menu) GetCookingInstruction(code string) (CookingInstruction, bool) {
+	for key, data := range m.CookingInstructions {
+		data, ok := data.(map[string]interface{})
+		if !ok || !strings.EqualFold(key, code) {
+			continue
+		}
+
+		instruction := CookingInstruction{Code: key}
+		instruction.Name = menuText(lookupKey(data, "Name"))
+		instruction.Description = menuText(lookupKey(data, "Description"))
+		instruction.Group = menuText(lookupKey(data, "Group"))
+		if instruction.Group == "" {
+			instruction.Group = menuText(lookupKey(data, "GroupCode"))
+		}
+		return instruction, true
+	}
+	return CookingInstruction{}, false
+}
+
+// GetCookingInstructions returns every cooking instruction on the menu, sorted by code
+func (m *Menu) GetCookingInstructions() []CookingInstruction {
+	instructions := make([]CookingInstruction, 0, len(m.CookingInstructions))
+	for _, code := range sortedKeys(m.CookingInstructions) {
+		if instruction, ok := m.GetCookingInstruction(code); ok {
+			instructions = append(instructions, instruction)
+		}
+	}
+	return instructions
+}
+
+// FindCookingInstruction finds a cooking instruction by code, or by the words of
+// its name such as "well done" or "square cut"
+func (m *Menu) FindCookingInstruction(instruction string) (CookingInstruction, bool) {
+	if found, ok := m.GetCookingInstruction(instruction); ok {
+		return found, true
+	}
+
+	words := toppingNameWords(instruction)
+	if len(words) == 0 {
+		return CookingInstruction{}, false
+	}
+
+	matches := make([]CookingInstruction, 0)
+	for _, candidate := range m.GetCookingInstructions() {
+		name := toppingNameWords(candidate.Name)
+		if strings.Join(name, " ") == strings.Join(words, " ") {
+			return candidate, true
+		}
+		if containsTokens(name, words) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) != 1 {
+		return CookingInstruction{}, false
+	}
+	return matches[0], true
+}
+
+// GetCookingInstructionGroup returns a group of cooking instructions by code.
+// Its instructions are those the group lists, or those naming it as their group.
+func (m *Menu) GetCookingInstructionGroup(code string) (CookingInstructionGroup, bool) {
+	group := CookingInstructionGroup{Code: code, MaxOptions: 1}
+
+	data, listed := m.CookingInstructionGroups[code].(map[string]interface{})
+	if listed {
+		tags, _ := lookupKey(data, "Tags").(map[string]interface{})
+		group.Name = menuText(lookupKey(data, "Name"))
+		for _, source := range []map[string]interface{}{data, tags} {
+			if limit := intValue(lookupKey(source, "MaxOptions")); limit > 0 {
+				group.MaxOptions = limit
+			}
+			for _, key := range []string{"ProductTypes", "ValidProductTypes"} {
+				group.ProductTypes = append(group.ProductTypes, stringList(lookupKey(source, key))...)
+			}
+		}
+		group.Instructions = stringList(lookupKey(data, "CookingInstructions"))
+	}
+
+	if len(group.Instructions) == 0 {
+		for _, instruction := range m.GetCookingInstructions() {
+			if strings.EqualFold(instruction.Group, code) {
+				group.Instructions = append(group.Instructions, instruction.Code)
+			}
+		}
+	}
+
+	if !listed && len(group.Instructions) == 0 {
+		return CookingInstructionGroup{}, false
+	}
+	return group, true
+}
+
+// CookingInstructionGroupsFor returns the groups of cooking instructions a
+// variant can have. A product or variant may list its groups or instructions;
+// otherwise a group applies to the product types it names, or to every product.
+func (m *Menu) CookingInstructionGroupsFor(variantCode string) []CookingInstructionGroup {
+	variant, _ := m.GetVariant(variantCode)
+	product, _ := m.GetProduct(menuText(variant["ProductCode"]))
+	productType := menuText(product["ProductType"])
+
+	// Groups or instructions listed by the variant or product
+	listed := make(map[string]bool)
+	for _, data := range []map[string]interface{}{variant, product} {
+		tags, _ := lookupKey(data, "Tags").(map[string]interface{})
+		for _, source := range []map[string]interface{}{data, tags} {
+			for _, code := range stringList(lookupKey(source, "CookingInstructionGroups")) {
+				listed[code] = true
+			}
+			for _, code := range stringList(lookupKey(source, "AvailableCookingInstructions")) {
+				if instruction, ok := m.GetCookingInstruction(code); ok {
+					listed[instruction.Group] = true
+				}
+			}
+		}
+	}
+
+	codes := make(map[string]bool)
+	for code := range m.CookingInstructionGroups {
+		codes[code] = true
+	}
+	for _, instruction := range m.GetCookingInstructions() {
+		if instruction.Group != "" {
+			codes[instruction.Group] = true
+		}
+	}
+
+	groups := make([]CookingInstructionGroup, 0)
+	for _, code := range sortedKeys(codes) {
+		group, ok := m.GetCookingInstructionGroup(code)
+		if !ok {
+			continue
+		}
+		switch {
+		case len(listed) > 0:
+			ok = listed[code]
+		case len(group.ProductTypes) > 0:
+			ok = containsFold(group.ProductTypes, productType)
+		}
+		if ok {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// ValidateCookingInstructions checks that an item's cooking instructions apply
+// to its product and that no group has more than its MaxOptions. Instructions
+// without a group are always allowed.
+func (m *Menu) ValidateCookingInstructions(item *Item) error {
+	groups := make(map[string]CookingInstructionGroup)
+	for _, group := range m.CookingInstructionGroupsFor(item.Code) {
+		groups[group.Code] = group
+	}
+
+	counts := make(map[string]int)
+	for _, instruction := range item.CookingInstructions(m) {
+		if instruction.Group == "" {
+			continue
+		}
+		group, ok := groups[instruction.Group]
+		if !ok {
+			return utils.NewDominosValidationError(instruction.Name + " is not available for " + item.Code)
+		}
+		counts[group.Code]++
+		if counts[group.Code] > group.MaxOptions {
+			return utils.NewDominosValidationError("Item " + item.Code + " can have at most " + strconv.Itoa(group.MaxOptions) + " " + groupLabel(group) + " instructions")
+		}
+	}
+	return nil
+}
+
+// CookingInstructions returns the cooking instructions in the item's options,
+// sorted by code
+func (i *Item) CookingInstructions(menu *Menu) []CookingInstruction {
+	codes := make([]string, 0)
+	for code := range i.Options {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	instructions := make([]CookingInstruction, 0)
+	for _, code := range codes {
+		if instruction, ok := menu.GetCookingInstruction(code); ok {
+			instructions = append(instructions, instruction)
+		}
+	}
+	return instructions
+}
+
+// AddCookingInstruction adds a cooking instruction, given by code or name, to the
+// item's options. If the instruction's group allows only one, it replaces the
+// item's other instruction from that group.
+func (i *Item) AddCookingInstruction(menu *Menu, instruction string) error {
+	found, ok := menu.FindCookingInstruction(instruction)
+	if !ok {
+		return utils.NewDominosProductsError("Cooking instruction " + instruction + " is not on the menu")
+	}
+
+	if i.Options == nil {
+		i.Options = make(map[string]interface{})
+	}
+
+	// Put the options back as they were if the instruction isn't allowed
+	replaced := make(map[string]interface{})
+	if previous, ok := i.Options[found.Code]; ok {
+		replaced[found.Code] = previous
+	}
+	group, ok := menu.GetCookingInstructionGroup(found.Group)
+	if found.Group != "" && ok && group.MaxOptions == 1 {
+		for _, existing := range i.CookingInstructions(menu) {
+			if existing.Group == found.Group {
+				replaced[existing.Code] = i.Options[existing.Code]
+				delete(i.Options, existing.Code)
+			}
+		}
+	}
+	i.Options[found.Code] = map[string]interface{}{PortionWhole: "1"}
+
+	if err := menu.ValidateCookingInstructions(i); err != nil {
+		delete(i.Options, found.Code)
+		for code, options := range replaced {
+			i.Options[code] = options
+		}
+		return err
+	}
+	return nil
+}
+
+// RemoveCookingInstruction takes a cooking instruction, given by code in any
+// case, off the item. Options that aren't cooking instructions are left alone.
+func (i *Item) RemoveCookingInstruction(menu *Menu, code string) {
+	instruction, ok := menu.GetCookingInstruction(code)
+	if !ok {
+		return
+	}
+	for key := range i.Options {
+		if strings.EqualFold(key, instruction.Code) {
+			delete(i.Options, key)
+		}
+	}
+}
+
+// groupLabel names a group for messages
+func groupLabel(group CookingInstructionGroup) string {
+	if group.Name != "" {
+		return strings.ToLower(group.Name)
+	}
+	return group.Code
+}
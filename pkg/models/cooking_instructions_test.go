@@ -0,0 +1,59 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAddCookingInstruction(t *testing.T) {
+	menu := loadMenu(t)
+
+	item := &Item{Code: "14SCREEN", Qty: 1, Options: map[string]interface{}{"P": map[string]interface{}{"1/1": "1"}}}
+	for _, instruction := range []string{"PIECT", "well done", "square cut", "GARLIC", "oregano"} {
+		if err := item.AddCookingInstruction(menu, instruction); err != nil {
+			t.Fatalf("AddCookingInstruction(%q) = %v", instruction, err)
+		}
+	}
+
+	var codes []string
+	for _, instruction := range item.CookingInstructions(menu) {
+		codes = append(codes, instruction.Code)
+	}
+	// SQCT replaces PIECT from the CUT group, and the instructions without a
+	// group are both kept
+	if want := []string{"GARLIC", "OREG", "SQCT", "WD"}; !reflect.DeepEqual(codes, want) {
+		t.Errorf("instructions = %v, want %v", codes, want)
+	}
+	if _, ok := item.Options["P"]; !ok {
+		t.Error("adding instructions removed a topping")
+	}
+
+	twists := &Item{Code: "B8PCPT", Qty: 1}
+	if err := twists.AddCookingInstruction(menu, CookingWellDone); err == nil {
+		t.Error("well done was allowed on bread twists")
+	}
+	if len(twists.Options) != 0 {
+		t.Errorf("rejected instruction left options %v", twists.Options)
+	}
+
+	if err := item.AddCookingInstruction(menu, "extra crispy"); err == nil {
+		t.Error("an instruction not on the menu was added")
+	}
+}
+
+func TestRemoveCookingInstruction(t *testing.T) {
+	menu := loadMenu(t)
+
+	item := &Item{Code: "14SCREEN", Qty: 1, Options: map[string]interface{}{"P": map[string]interface{}{"1/1": "1"}}}
+	if err := item.AddCookingInstruction(menu, CookingSquareCut); err != nil {
+		t.Fatal(err)
+	}
+
+	item.RemoveCookingInstruction(menu, "P")
+	item.RemoveCookingInstruction(menu, "sqct")
+
+	want := map[string]interface{}{"P": map[string]interface{}{"1/1": "1"}}
+	if !reflect.DeepEqual(item.Options, want) {
+		t.Errorf("Options = %v, want only the pepperoni", item.Options)
+	}
+}
@@ -0,0 +1,264 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// Coupon represents a coupon applied to an order. Code, Qty, ID and Status are
+// exchanged with Domino's; the rest is filled in by Store.GetCoupon or Menu.GetCoupon.
+type Coupon struct {
+	DominosFormat
+	Code   string `json:"code"`
+	Qty    int    `json:"qty"`
+	ID     int    `json:"id"`
+	IsNew  bool   `json:"isNew"`
+	Status int    `json:"status"`
+	// Fulfilled is reported by Domino's once the order has been priced
+	Fulfilled bool `json:"fulfilled,omitempty"`
+
+	Name        string `json:"-"`
+	Description string `json:"-"`
+	Price       Money  `json:"-"`
+	// ServiceMethods the coupon is valid for, or any if empty
+	ServiceMethods []string `json:"-"`
+	// ProductGroups the order must contain for the coupon to apply
+	ProductGroups []CouponProductGroup `json:"-"`
+}
+
+// CouponProductGroup is a set of products a coupon requires RequiredQty of, such
+// as two medium pizzas
+type CouponProductGroup struct {
+	Code         string
+	RequiredQty  int
+	ProductCodes []string
+}
+
+// NewCoupon creates a coupon from its code, without any fulfillment rules
+func NewCoupon(code string) *Coupon {
+	return &Coupon{
+		Code:  code,
+		Qty:   1,
+		IsNew: true,
+	}
+}
+
+// Matches reports whether a product or variant code belongs to the group
+func (g CouponProductGroup) Matches(code string) bool {
+	for _, productCode := range g.ProductCodes {
+		if strings.EqualFold(productCode, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceptsServiceMethod reports whether the coupon is valid for a service method
+func (c *Coupon) AcceptsServiceMethod(serviceMethod string) bool {
+	if len(c.ServiceMethods) == 0 {
+		return true
+	}
+
+	for _, method := range c.ServiceMethods {
+		if strings.EqualFold(method, serviceMethod) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that an order satisfies the coupon's service methods and
+// product groups
+func (c *Coupon) Validate(order *Order) error {
+	if !c.AcceptsServiceMethod(order.ServiceMethod) {
+		return utils.NewDominosCouponError("Coupon " + c.Code + " is not valid for " + order.ServiceMethod + " orders")
+	}
+
+	if shortfalls := c.Missing(order); len(shortfalls) > 0 {
+		shortfall := shortfalls[0]
+		return utils.NewDominosCouponError("Coupon " + c.Code + " needs " + strconv.Itoa(shortfall.Missing) + " more of " + strings.Join(shortfall.Group.ProductCodes, ", "))
+	}
+
+	return nil
+}
+
+// mergeCoupons copies the IDs and statuses of coupons returned by the API onto
+// the caller's coupons, matching them by code, so the caller's pointers still
+// work with RemoveCoupon. Coupons Domino's dropped are left unfulfilled and
+// coupons it added are appended.
+func mergeCoupons(coupons []*Coupon, returned []*Coupon) []*Coupon {
+	matched := make([]bool, len(coupons))
+	for _, coupon := range coupons {
+		coupon.Fulfilled = false
+	}
+
+	for _, r := range returned {
+		if r == nil {
+			continue
+		}
+
+		found := false
+		for i, coupon := range coupons[:len(matched)] {
+			if !matched[i] && strings.EqualFold(coupon.Code, r.Code) {
+				coupon.ID = r.ID
+				coupon.Status = r.Status
+				coupon.Fulfilled = r.Fulfilled
+				matched[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			coupons = append(coupons, r)
+		}
+	}
+	return coupons
+}
+
+// GetFormatted returns the coupon as a map with PascalCase keys
+func (c *Coupon) GetFormatted() map[string]interface{} {
+	return getFormatted(c)
+}
+
+// SetFormatted updates the coupon from a map with keys in any format
+func (c *Coupon) SetFormatted(data map[string]interface{}) {
+	setFormatted(c, data)
+}
+
+// GetCoupon returns a coupon from the menu. Menus don't always list a coupon's
+// product groups; use Store.GetCoupon to load them from Domino's.
+func (m *Menu) GetCoupon(code string) (*Coupon, bool) {
+	data, ok := m.Coupons[code].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	return m.newCoupon(code, data), true
+}
+
+// GetCoupon loads a coupon with its fulfillment rules from the store's coupon
+// endpoint. The menu, if given, adds the variants of the products it requires.
+func (s *Store) GetCoupon(code string, menu *Menu) (*Coupon, error) {
+	if s.StoreID == "" {
+		return nil, utils.NewDominosStoreError("Store ID is required to get a coupon")
+	}
+	if code == "" {
+		return nil, utils.NewDominosCouponError("Coupon code is required")
+	}
+
+	lang := utils.CurrentLanguage()
+	if lang == "" {
+		lang = "en"
+	}
+
+	url := strings.NewReplacer(
+		"${storeID}", s.StoreID,
+		"${couponCode}", code,
+		"${lang}", lang,
+	).Replace(utils.URLs.Store.Coupon)
+
+	response, err := utils.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if len(response) == 0 {
+		return nil, utils.NewDominosCouponError("Coupon " + code + " was not found")
+	}
+
+	return menu.newCoupon(code, response), nil
+}
+
+// newCoupon reads a coupon from a menu entry or a coupon endpoint response.
+// m may be nil, in which case product codes aren't expanded.
+func (m *Menu) newCoupon(code string, data map[string]interface{}) *Coupon {
+	coupon := NewCoupon(code)
+	coupon.Name, _ = data["Name"].(string)
+	coupon.Description, _ = data["Description"].(string)
+	if price, err := menuPrice(data["Price"], m.currency()); err == nil {
+		coupon.Price = price
+	}
+
+	tags, _ := data["Tags"].(map[string]interface{})
+	coupon.ServiceMethods = stringList(tags["ValidServiceMethods"])
+	if len(coupon.ServiceMethods) == 0 {
+		coupon.ServiceMethods = stringList(tags["ServiceMethods"])
+	}
+
+	// Product groups are listed on the coupon itself, or in its tags
+	groups, ok := data["ProductGroups"].([]interface{})
+	if !ok {
+		groups, _ = tags["ProductGroups"].([]interface{})
+	}
+	for _, data := range groups {
+		if data, ok := data.(map[string]interface{}); ok {
+			group := couponProductGroup(data)
+			if m != nil {
+				group.ProductCodes = m.expandProductCodes(group.ProductCodes)
+			}
+			coupon.ProductGroups = append(coupon.ProductGroups, group)
+		}
+	}
+
+	coupon.SetDominosAPIResponse(data)
+
+	return coupon
+}
+
+// expandProductCodes adds the variants of any product codes, since order items
+// are variants
+func (m *Menu) expandProductCodes(codes []string) []string {
+	expanded := make([]string, 0, len(codes))
+	for _, code := range codes {
+		expanded = append(expanded, code)
+		if product, ok := m.GetProduct(code); ok {
+			expanded = append(expanded, stringList(product["Variants"])...)
+		}
+	}
+	return expanded
+}
+
+// GetCoupons returns every coupon on the menu, sorted by code
+func (m *Menu) GetCoupons() []*Coupon {
+	codes := make([]string, 0, len(m.Coupons))
+	for code := range m.Coupons {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	coupons := make([]*Coupon, 0, len(codes))
+	for _, code := range codes {
+		if coupon, ok := m.GetCoupon(code); ok {
+			coupons = append(coupons, coupon)
+		}
+	}
+	return coupons
+}
+
+// couponProductGroup reads a product group from the menu
+func couponProductGroup(data map[string]interface{}) CouponProductGroup {
+	group := CouponProductGroup{RequiredQty: 1}
+
+	for _, key := range []string{"GroupCode", "Code"} {
+		if code, ok := data[key].(string); ok && code != "" {
+			group.Code = code
+			break
+		}
+	}
+	for _, key := range []string{"RequiredQty", "Qty"} {
+		if qty, ok := data[key]; ok {
+			group.RequiredQty = intValue(qty)
+			break
+		}
+	}
+	for _, key := range []string{"ProductCodes", "Products"} {
+		if codes := stringList(data[key]); len(codes) > 0 {
+			group.ProductCodes = codes
+			break
+		}
+	}
+
+	return group
+}
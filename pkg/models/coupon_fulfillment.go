@@ -0,0 +1,159 @@
+package models
+
+import (
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// CouponShortfall is what an order is missing from one of a coupon's product groups
+type CouponShortfall struct {
+	Group CouponProductGroup
+	// Missing is how many more products from the group the order needs
+	Missing int
+}
+
+// Missing returns the coupon's product groups the order doesn't satisfy yet, with
+// how many more products each needs for every use of the coupon. Each product
+// counts toward one group only, so one pizza can't fill both halves of "2 pizzas
+// and 1 pizza".
+func (c *Coupon) Missing(order *Order) []CouponShortfall {
+	qty := c.Qty
+	if qty < 1 {
+		qty = 1
+	}
+
+	shortfalls := make([]CouponShortfall, 0)
+	for i, have := range c.allocateProducts(order, qty) {
+		group := c.ProductGroups[i]
+		if missing := group.RequiredQty*qty - have; missing > 0 {
+			shortfalls = append(shortfalls, CouponShortfall{Group: group, Missing: missing})
+		}
+	}
+	return shortfalls
+}
+
+// allocateProducts shares the order's products out among the coupon's product
+// groups, each product going to at most one group, so that as many of the
+// required products as possible are covered. It returns how many products each
+// group gets. This is a maximum flow from the items, through the groups they
+// match, to the groups' requirements, found with breadth-first augmenting paths.
+func (c *Coupon) allocateProducts(order *Order, qty int) []int {
+	items, groups := order.Products, c.ProductGroups
+	source, sink := 0, len(items)+len(groups)+1
+	itemNode := func(i int) int { return 1 + i }
+	groupNode := func(g int) int { return 1 + len(items) + g }
+
+	capacity := make([][]int, sink+1)
+	for node := range capacity {
+		capacity[node] = make([]int, sink+1)
+	}
+	for i, item := range items {
+		capacity[source][itemNode(i)] = item.Qty
+		for g, group := range groups {
+			if group.Matches(item.Code) {
+				capacity[itemNode(i)][groupNode(g)] = item.Qty
+			}
+		}
+	}
+	for g, group := range groups {
+		capacity[groupNode(g)][sink] = group.RequiredQty * qty
+	}
+
+	for {
+		parent := make([]int, sink+1)
+		for node := range parent {
+			parent[node] = -1
+		}
+		parent[source] = source
+		queue := []int{source}
+		for len(queue) > 0 && parent[sink] == -1 {
+			node := queue[0]
+			queue = queue[1:]
+			for next, left := range capacity[node] {
+				if left > 0 && parent[next] == -1 {
+					parent[next] = node
+					queue = append(queue, next)
+				}
+			}
+		}
+		if parent[sink] == -1 {
+			break
+		}
+
+		flow := -1
+		for node := sink; node != source; node = parent[node] {
+			if left := capacity[parent[node]][node]; flow == -1 || left < flow {
+				flow = left
+			}
+		}
+		for node := sink; node != source; node = parent[node] {
+			capacity[parent[node]][node] -= flow
+			capacity[node][parent[node]] += flow
+		}
+	}
+
+	allocated := make([]int, len(groups))
+	for g := range groups {
+		allocated[g] = capacity[sink][groupNode(g)]
+	}
+	return allocated
+}
+
+// FulfillCoupon adds the default product of each of the coupon's product groups
+// the order is short of, and returns the items added. The default is the group's
+// first product code found on the menu, or its first code if menu is nil.
+func (o *Order) FulfillCoupon(coupon *Coupon, menu *Menu) ([]*Item, error) {
+	added := make([]*Item, 0)
+	for _, shortfall := range coupon.Missing(o) {
+		code, ok := defaultCouponProduct(shortfall.Group, menu)
+		if !ok {
+			return added, utils.NewDominosCouponError("Coupon " + coupon.Code + " has no product on the menu for group " + shortfall.Group.Code)
+		}
+
+		item, err := NewItem(map[string]interface{}{"code": code, "qty": shortfall.Missing})
+		if err != nil {
+			return added, err
+		}
+		o.AddItem(item)
+		added = append(added, item)
+	}
+
+	return added, nil
+}
+
+// UnfulfilledCoupons returns the order's coupons that Domino's reported as not
+// fulfilled when pricing, or, before pricing, that are missing products
+func (o *Order) UnfulfilledCoupons() []*Coupon {
+	coupons := make([]*Coupon, 0)
+	for _, coupon := range o.Coupons {
+		if o.priceResponse != nil && !coupon.Fulfilled {
+			coupons = append(coupons, coupon)
+		} else if o.priceResponse == nil && len(coupon.Missing(o)) > 0 {
+			coupons = append(coupons, coupon)
+		}
+	}
+	return coupons
+}
+
+// defaultCouponProduct picks the variant code added to fill a product group
+func defaultCouponProduct(group CouponProductGroup, menu *Menu) (string, bool) {
+	if menu == nil {
+		if len(group.ProductCodes) == 0 {
+			return "", false
+		}
+		return group.ProductCodes[0], true
+	}
+
+	for _, code := range group.ProductCodes {
+		if _, ok := menu.GetVariant(code); ok {
+			return code, true
+		}
+
+		// A product code stands for its first variant
+		if product, ok := menu.GetProduct(code); ok {
+			if variants := stringList(product["Variants"]); len(variants) > 0 {
+				return variants[0], true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,135 @@
+package models
+
+import "testing"
+
+// groupCoupon reads a coupon with product groups from the test menu. Each group
+// is a required quantity followed by product codes.
+func groupCoupon(t *testing.T, groups ...[]interface{}) *Coupon {
+	t.Helper()
+
+	data := make([]interface{}, len(groups))
+	for i, group := range groups {
+		data[i] = map[string]interface{}{
+			"GroupCode":    string(rune('1' + i)),
+			"RequiredQty":  group[0],
+			"ProductCodes": group[1:],
+		}
+	}
+	return loadMenu(t).newCoupon("9193", map[string]interface{}{"ProductGroups": data})
+}
+
+// totalMissing adds up a coupon's shortfalls
+func totalMissing(shortfalls []CouponShortfall) int {
+	total := 0
+	for _, shortfall := range shortfalls {
+		total += shortfall.Missing
+	}
+	return total
+}
+
+func TestCouponMissing(t *testing.T) {
+	pizzas := []interface{}{2.0, "S_PIZZA"}
+	pizza := []interface{}{1.0, "S_PIZZA"}
+	pizzaOrBread := []interface{}{1.0, "S_PIZZA", "F_PARMT"}
+
+	tests := []struct {
+		name    string
+		coupon  *Coupon
+		items   []*Item
+		missing int
+	}{
+		{"one group met", groupCoupon(t, pizzas), []*Item{{Code: "14SCREEN", Qty: 2}}, 0},
+		{"one group short", groupCoupon(t, pizzas), []*Item{{Code: "14SCREEN", Qty: 1}}, 1},
+		{"one pizza for two groups", groupCoupon(t, pizzas, pizza), []*Item{{Code: "14SCREEN", Qty: 1}}, 2},
+		{"three pizzas for two groups", groupCoupon(t, pizzas, pizza), []*Item{{Code: "14SCREEN", Qty: 1}, {Code: "12SCREEN", Qty: 2}}, 0},
+		{"bread goes where the pizza can't", groupCoupon(t, pizzaOrBread, pizza), []*Item{{Code: "B8PCPT", Qty: 1}, {Code: "14SCREEN", Qty: 1}}, 0},
+		{"pizza goes where the bread can't", groupCoupon(t, pizza, pizzaOrBread), []*Item{{Code: "14SCREEN", Qty: 1}, {Code: "B8PCPT", Qty: 1}}, 0},
+		{"products outside the groups", groupCoupon(t, pizza), []*Item{{Code: "2LCOKE", Qty: 3}}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &Order{}
+			for _, item := range tt.items {
+				order.AddItem(item)
+			}
+			shortfalls := tt.coupon.Missing(order)
+			if got := totalMissing(shortfalls); got != tt.missing {
+				t.Errorf("Missing = %+v, want %d products in all", shortfalls, tt.missing)
+			}
+			if err := tt.coupon.Validate(order); (err == nil) != (tt.missing == 0) {
+				t.Errorf("Validate = %v, want an error only when products are missing", err)
+			}
+		})
+	}
+
+	// Using the coupon twice needs twice the products
+	twice := groupCoupon(t, pizzas, pizza)
+	twice.Qty = 2
+	order := &Order{}
+	order.AddItem(&Item{Code: "14SCREEN", Qty: 4})
+	if got := totalMissing(twice.Missing(order)); got != 2 {
+		t.Errorf("Missing for two uses = %d, want 2", got)
+	}
+}
+
+func TestFulfillCoupon(t *testing.T) {
+	menu := loadMenu(t)
+	coupon := groupCoupon(t, []interface{}{2.0, "S_PIZZA"}, []interface{}{1.0, "F_PARMT"})
+
+	order := &Order{}
+	order.AddItem(&Item{Code: "14SCREEN", Qty: 1})
+
+	added, err := order.FulfillCoupon(coupon, menu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 2 {
+		t.Fatalf("added %d items, want a pizza and the twists", len(added))
+	}
+	// A product code stands for its first variant
+	if added[0].Code != "10SCREEN" || added[0].Qty != 1 {
+		t.Errorf("first item = %s x%d, want 10SCREEN x1", added[0].Code, added[0].Qty)
+	}
+	if added[1].Code != "B8PCPT" || added[1].Qty != 1 {
+		t.Errorf("second item = %s x%d, want B8PCPT x1", added[1].Code, added[1].Qty)
+	}
+	if len(order.Products) != 3 || order.Products[1] != added[0] || order.Products[2] != added[1] {
+		t.Errorf("order products = %v, want the pizza and the added items", order.Products)
+	}
+	if missing := coupon.Missing(order); len(missing) != 0 {
+		t.Errorf("still missing %+v", missing)
+	}
+
+	// Nothing is added once the coupon is met
+	if added, err := order.FulfillCoupon(coupon, menu); err != nil || len(added) != 0 {
+		t.Errorf("FulfillCoupon again = %v, %v, want nothing added", added, err)
+	}
+
+	unknown := groupCoupon(t, []interface{}{1.0, "S_NOTONMENU"})
+	if _, err := order.FulfillCoupon(unknown, menu); err == nil {
+		t.Error("FulfillCoupon found a product for a group that isn't on the menu")
+	}
+}
+
+func TestUnfulfilledCoupons(t *testing.T) {
+	met := groupCoupon(t, []interface{}{1.0, "S_PIZZA"})
+	short := groupCoupon(t, []interface{}{2.0, "S_PIZZA"})
+	short.Code = "9174"
+
+	order := &Order{}
+	order.AddItem(&Item{Code: "14SCREEN", Qty: 1})
+	order.AddCoupon(met).AddCoupon(short)
+
+	// Before pricing, by the products the order has
+	if got := order.UnfulfilledCoupons(); len(got) != 1 || got[0] != short {
+		t.Errorf("unpriced UnfulfilledCoupons = %v, want 9174", got)
+	}
+
+	// After pricing, as Domino's reports them
+	order.priceResponse = map[string]interface{}{}
+	met.Fulfilled, short.Fulfilled = false, true
+	if got := order.UnfulfilledCoupons(); len(got) != 1 || got[0] != met {
+		t.Errorf("priced UnfulfilledCoupons = %v, want 9193", got)
+	}
+}
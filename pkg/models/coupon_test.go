@@ -0,0 +1,96 @@
+package models
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+func TestUpdateKeepsCallerCoupons(t *testing.T) {
+	order := &Order{Currency: "USD"}
+	first := NewCoupon("9193")
+	second := NewCoupon("9174")
+	order.AddCoupon(first).AddCoupon(second)
+
+	// Domino's drops 9174 and adds a coupon of its own
+	order.update(map[string]interface{}{
+		"Coupons": []interface{}{
+			map[string]interface{}{"Code": "8888", "ID": 3.0, "Status": 0.0, "Fulfilled": true},
+			map[string]interface{}{"Code": "9193", "ID": 1.0, "Status": 0.0, "Fulfilled": true},
+		},
+	})
+
+	if len(order.Coupons) != 3 || order.Coupons[0] != first || order.Coupons[1] != second {
+		t.Fatalf("Coupons = %v, want the caller's two coupons followed by 8888", order.Coupons)
+	}
+	if first.ID != 1 || !first.Fulfilled {
+		t.Errorf("9193 = ID %d, fulfilled %v, want ID 1 and fulfilled", first.ID, first.Fulfilled)
+	}
+	if second.Fulfilled {
+		t.Error("9174 was dropped by Domino's but is marked fulfilled")
+	}
+	if order.Coupons[2].Code != "8888" {
+		t.Errorf("added coupon = %q, want 8888", order.Coupons[2].Code)
+	}
+
+	order.RemoveCoupon(second)
+	if len(order.Coupons) != 2 || order.Coupons[0] != first {
+		t.Errorf("RemoveCoupon after update left %v", order.Coupons)
+	}
+}
+
+func TestStoreGetCoupon(t *testing.T) {
+	var path string
+	server := jsonServer(t, func(r *http.Request) interface{} {
+		path = r.URL.Path
+		return map[string]interface{}{
+			"Code":  "9193",
+			"Name":  "2 Medium Pizzas",
+			"Price": "13.98",
+			"Tags": map[string]interface{}{
+				"ValidServiceMethods": []interface{}{"Carryout", "Delivery"},
+			},
+			"ProductGroups": []interface{}{
+				map[string]interface{}{
+					"GroupCode":    "1",
+					"RequiredQty":  2.0,
+					"ProductCodes": []interface{}{"S_PIZZA"},
+				},
+			},
+		}
+	})
+	useURLs(t, func(urls *utils.URLConfig) {
+		urls.Store.Coupon = server.URL + "/power/store/${storeID}/coupon/${couponCode}?lang=${lang}"
+	})
+
+	menu := &Menu{Products: map[string]interface{}{
+		"S_PIZZA": map[string]interface{}{"Variants": []interface{}{"12SCREEN", "14SCREEN"}},
+	}}
+
+	coupon, err := (&Store{StoreID: "4336"}).GetCoupon("9193", menu)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if path != "/power/store/4336/coupon/9193" {
+		t.Errorf("requested %q", path)
+	}
+	if coupon.Name != "2 Medium Pizzas" || !coupon.AcceptsServiceMethod("Delivery") {
+		t.Errorf("coupon = %+v", coupon)
+	}
+	if len(coupon.ProductGroups) != 1 {
+		t.Fatalf("ProductGroups = %v, want one group", coupon.ProductGroups)
+	}
+	group := coupon.ProductGroups[0]
+	if group.RequiredQty != 2 || !group.Matches("12SCREEN") {
+		t.Errorf("group = %+v, want 2 of S_PIZZA including its variants", group)
+	}
+
+	order := &Order{ServiceMethod: "Delivery"}
+	order.AddItem(&Item{Code: "12SCREEN", Qty: 1})
+	if err := coupon.Validate(order); err == nil || !strings.Contains(err.Error(), "1 more") {
+		t.Errorf("Validate = %v, want one more pizza needed", err)
+	}
+}
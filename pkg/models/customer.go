@@ -30,3 +30,13 @@ func NewCustomer(customerData map[string]interface{}) (*Customer, error) {
 
 	return customer, nil
 }
+
+// GetFormatted returns the customer as a map with PascalCase keys
+func (c *Customer) GetFormatted() map[string]interface{} {
+	return getFormatted(c)
+}
+
+// SetFormatted updates the customer from a map with keys in any format
+func (c *Customer) SetFormatted(data map[string]interface{}) {
+	setFormatted(c, data)
+}
@@ -0,0 +1,256 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// Defaults for a DealFinder
+const (
+	DefaultDealConcurrency = 4
+	DefaultDealCouponLimit = 2
+	DefaultDealCandidates  = 50
+)
+
+// Deal is a set of coupons that lowers an order's total
+type Deal struct {
+	Coupons []*Coupon
+	// Order is a priced copy of the order with the coupons added
+	Order   *Order
+	Total   Money
+	Savings Money
+}
+
+// DealFinder prices an order with the coupons on a store's menu to find the
+// cheapest ones. Prices are cached, so a finder can be reused as the cart changes.
+type DealFinder struct {
+	Menu *Menu
+	// Concurrency is the most price requests made at once
+	Concurrency int
+	// CouponLimit is the most coupons combined in one deal
+	CouponLimit int
+	// MaxCandidates is the most coupon combinations priced per order
+	MaxCandidates int
+
+	mu    sync.Mutex
+	cache map[string]*Order
+}
+
+// NewDealFinder creates a deal finder for a store's menu
+func NewDealFinder(menu *Menu) *DealFinder {
+	return &DealFinder{
+		Menu:          menu,
+		Concurrency:   DefaultDealConcurrency,
+		CouponLimit:   DefaultDealCouponLimit,
+		MaxCandidates: DefaultDealCandidates,
+		cache:         make(map[string]*Order),
+	}
+}
+
+// ApplicableCoupons returns the menu's coupons that are valid for the order's
+// service method, that the order satisfies, and that aren't already on it
+func (f *DealFinder) ApplicableCoupons(order *Order) []*Coupon {
+	applied := make(map[string]bool, len(order.Coupons))
+	for _, coupon := range order.Coupons {
+		applied[strings.ToUpper(coupon.Code)] = true
+	}
+
+	coupons := make([]*Coupon, 0)
+	for _, coupon := range f.Menu.GetCoupons() {
+		if applied[strings.ToUpper(coupon.Code)] {
+			continue
+		}
+		if coupon.Validate(order) == nil {
+			coupons = append(coupons, coupon)
+		}
+	}
+	return coupons
+}
+
+// FindDeals prices the order alone and with each applicable coupon, then combines
+// the coupons that saved money, up to CouponLimit at a time. It returns the deals
+// that lower the total, biggest savings first. Combinations Domino's rejects are
+// left out rather than reported as errors.
+func (f *DealFinder) FindDeals(ctx context.Context, order *Order) ([]*Deal, error) {
+	base, err := f.price(ctx, order, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Price each coupon on its own
+	candidates := make([][]*Coupon, 0)
+	for _, coupon := range f.ApplicableCoupons(order) {
+		candidates = append(candidates, []*Coupon{coupon})
+	}
+	deals, err := f.priceDeals(ctx, order, base, f.limitCandidates(candidates))
+	if err != nil {
+		return nil, err
+	}
+
+	// Then combinations of the coupons that saved money
+	saving := make([]*Coupon, len(deals))
+	for i, deal := range deals {
+		saving[i] = deal.Coupons[0]
+	}
+	combinations := make([][]*Coupon, 0)
+	for size := 2; size <= f.CouponLimit && size <= len(saving); size++ {
+		combinations = append(combinations, couponCombinations(saving, size)...)
+	}
+	if f.MaxCandidates > 0 {
+		remaining := f.MaxCandidates - len(candidates)
+		if remaining < 0 {
+			remaining = 0
+		}
+		if len(combinations) > remaining {
+			combinations = combinations[:remaining]
+		}
+	}
+
+	combined, err := f.priceDeals(ctx, order, base, combinations)
+	if err != nil {
+		return nil, err
+	}
+	deals = append(deals, combined...)
+
+	sort.SliceStable(deals, func(i, j int) bool {
+		if deals[i].Savings.Amount != deals[j].Savings.Amount {
+			return deals[i].Savings.Amount > deals[j].Savings.Amount
+		}
+		return len(deals[i].Coupons) < len(deals[j].Coupons)
+	})
+
+	return deals, nil
+}
+
+// priceDeals prices each combination of coupons with at most Concurrency
+// requests at once, and returns those that beat the base total
+func (f *DealFinder) priceDeals(ctx context.Context, order *Order, base *Order, candidates [][]*Coupon) ([]*Deal, error) {
+	concurrency := f.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]*Deal, len(candidates))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, coupons := range candidates {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(i int, coupons []*Coupon) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			priced, err := f.price(ctx, order, coupons)
+			if err != nil {
+				return
+			}
+
+			savings, err := base.Total().Sub(priced.Total())
+			if err != nil || savings.Amount <= 0 {
+				return
+			}
+			results[i] = &Deal{Coupons: coupons, Order: priced, Total: priced.Total(), Savings: savings}
+		}(i, coupons)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	deals := make([]*Deal, 0, len(results))
+	for _, deal := range results {
+		if deal != nil {
+			deals = append(deals, deal)
+		}
+	}
+	return deals, nil
+}
+
+// price returns a priced copy of the order with the coupons added, from the
+// cache if the same cart has been priced before. The request is aborted when
+// ctx is done.
+func (f *DealFinder) price(ctx context.Context, order *Order, coupons []*Coupon) (*Order, error) {
+	priced := order.clone()
+	for _, coupon := range coupons {
+		copied := *coupon
+		priced.AddCoupon(&copied)
+	}
+
+	key := dealCacheKey(priced)
+	f.mu.Lock()
+	if f.cache == nil {
+		f.cache = make(map[string]*Order)
+	}
+	cached, ok := f.cache[key]
+	f.mu.Unlock()
+	if ok {
+		return cached.clone(), nil
+	}
+
+	if err := priced.PriceContext(ctx); err != nil {
+		return nil, err
+	}
+	if !priced.isPriced() {
+		return nil, utils.NewDominosPriceError("Price response has no customer total")
+	}
+
+	f.mu.Lock()
+	f.cache[key] = priced.clone()
+	f.mu.Unlock()
+
+	return priced, nil
+}
+
+// limitCandidates drops combinations past MaxCandidates
+func (f *DealFinder) limitCandidates(candidates [][]*Coupon) [][]*Coupon {
+	if f.MaxCandidates > 0 && len(candidates) > f.MaxCandidates {
+		return candidates[:f.MaxCandidates]
+	}
+	return candidates
+}
+
+// dealCacheKey identifies an order by what affects its price
+func dealCacheKey(order *Order) string {
+	coupons := make([]string, len(order.Coupons))
+	for i, coupon := range order.Coupons {
+		coupons[i] = strings.ToUpper(coupon.Code)
+	}
+	sort.Strings(coupons)
+
+	key, _ := json.Marshal(map[string]interface{}{
+		"StoreID":       order.StoreID,
+		"ServiceMethod": order.ServiceMethod,
+		"Products":      order.Products,
+		"Coupons":       coupons,
+	})
+	return string(key)
+}
+
+// couponCombinations returns every combination of size coupons, in order
+func couponCombinations(coupons []*Coupon, size int) [][]*Coupon {
+	if size == 0 {
+		return [][]*Coupon{{}}
+	}
+
+	combinations := make([][]*Coupon, 0)
+	for i := 0; i+size <= len(coupons); i++ {
+		for _, rest := range couponCombinations(coupons[i+1:], size-1) {
+			combination := append([]*Coupon{coupons[i]}, rest...)
+			combinations = append(combinations, combination)
+		}
+	}
+	return combinations
+}
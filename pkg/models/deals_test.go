@@ -0,0 +1,292 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// dealSavings is what each coupon takes off the test order's 25.00 total. BAD is
+// rejected by Domino's and NONE saves nothing.
+var dealSavings = map[string]int64{"A": 300, "B": 500, "E": 800, "NONE": 0, "BAD": 0}
+
+// dealMenu returns a menu with a coupon for each of dealSavings
+func dealMenu() *Menu {
+	coupons := make(map[string]interface{}, len(dealSavings))
+	for code := range dealSavings {
+		coupons[code] = map[string]interface{}{"Code": code, "Name": "Coupon " + code}
+	}
+	return &Menu{Coupons: coupons}
+}
+
+// dealOrder returns an order for one large pizza
+func dealOrder() *Order {
+	order := &Order{StoreID: "4336", ServiceMethod: "Delivery", Currency: "USD"}
+	order.AddItem(&Item{Code: "14SCREEN", Qty: 1})
+	return order
+}
+
+// requestCoupons reads the coupon codes from a price request
+func requestCoupons(t *testing.T, r *http.Request) []string {
+	var payload struct {
+		Order struct {
+			Coupons []struct{ Code string }
+		}
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		t.Error(err)
+	}
+
+	codes := make([]string, len(payload.Order.Coupons))
+	for i, coupon := range payload.Order.Coupons {
+		codes[i] = coupon.Code
+	}
+	return codes
+}
+
+// priceServer prices orders at 25.00 less each coupon's savings, counting the
+// requests it answers
+func priceServer(t *testing.T, requests *int32) {
+	server := jsonServer(t, func(r *http.Request) interface{} {
+		atomic.AddInt32(requests, 1)
+
+		total := int64(2500)
+		for _, code := range requestCoupons(t, r) {
+			if code == "BAD" {
+				return map[string]interface{}{"Status": -1.0, "Order": map[string]interface{}{"Status": -1.0}}
+			}
+			total -= dealSavings[code]
+		}
+		return map[string]interface{}{
+			"Status": 0.0,
+			"Order": map[string]interface{}{
+				"Status":           0.0,
+				"AmountsBreakdown": map[string]interface{}{"Customer": float64(total) / 100, "FoodAndBeverage": 20.0},
+			},
+		}
+	})
+	useURLs(t, func(urls *utils.URLConfig) { urls.Order.Price = server.URL })
+}
+
+// dealCodes lists the coupon codes of each deal
+func dealCodes(deals []*Deal) []string {
+	codes := make([]string, len(deals))
+	for i, deal := range deals {
+		combination := make([]string, len(deal.Coupons))
+		for j, coupon := range deal.Coupons {
+			combination[j] = coupon.Code
+		}
+		codes[i] = strings.Join(combination, "+")
+	}
+	return codes
+}
+
+func TestFindDeals(t *testing.T) {
+	var requests int32
+	priceServer(t, &requests)
+
+	finder := NewDealFinder(dealMenu())
+	order := dealOrder()
+	deals, err := finder.FindDeals(context.Background(), order)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Biggest savings first, fewer coupons first on a tie; NONE saves nothing
+	// and BAD is rejected, so neither is a deal
+	want := []string{"B+E", "A+E", "E", "A+B", "B", "A"}
+	if got := dealCodes(deals); !reflect.DeepEqual(got, want) {
+		t.Fatalf("deals = %v, want %v", got, want)
+	}
+	if deals[0].Savings != NewMoney(1300, "USD") || deals[0].Total != NewMoney(1200, "USD") {
+		t.Errorf("best deal saves %v for %v, want 13.00 for 12.00", deals[0].Savings, deals[0].Total)
+	}
+	if len(order.Coupons) != 0 || order.isPriced() {
+		t.Error("FindDeals changed the order it was given")
+	}
+
+	// The base order, five coupons on their own, and three pairs
+	if requests != 9 {
+		t.Errorf("made %d price requests, want 9", requests)
+	}
+
+	// The same carts are answered from the cache, except the rejected one
+	if _, err := finder.FindDeals(context.Background(), dealOrder()); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 10 {
+		t.Errorf("repeating the search made %d more requests, want 1 for BAD", requests-9)
+	}
+
+	// A different cart isn't
+	bigger := dealOrder()
+	bigger.Products[0].Qty = 2
+	if _, err := finder.FindDeals(context.Background(), bigger); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 19 {
+		t.Errorf("a different cart made %d more requests, want 9", requests-10)
+	}
+}
+
+func TestFindDealsLimits(t *testing.T) {
+	var requests int32
+	priceServer(t, &requests)
+
+	finder := NewDealFinder(dealMenu())
+	finder.CouponLimit = 1
+	deals, err := finder.FindDeals(context.Background(), dealOrder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dealCodes(deals), []string{"E", "B", "A"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("deals with CouponLimit 1 = %v, want %v", got, want)
+	}
+
+	finder = NewDealFinder(dealMenu())
+	finder.MaxCandidates = 2
+	deals, err = finder.FindDeals(context.Background(), dealOrder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Coupons are tried in code order, so only A and B are priced
+	if got, want := dealCodes(deals), []string{"B", "A"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("deals with MaxCandidates 2 = %v, want %v", got, want)
+	}
+}
+
+func TestFindDealsConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, most := 0, 0
+	server := jsonServer(t, func(r *http.Request) interface{} {
+		mu.Lock()
+		inFlight++
+		if inFlight > most {
+			most = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		total := 2500 - dealSavings[strings.Join(requestCoupons(t, r), "")]
+		return map[string]interface{}{
+			"Order": map[string]interface{}{"AmountsBreakdown": map[string]interface{}{"Customer": float64(total) / 100}},
+		}
+	})
+	useURLs(t, func(urls *utils.URLConfig) { urls.Order.Price = server.URL })
+
+	finder := NewDealFinder(dealMenu())
+	finder.Concurrency = 2
+	finder.CouponLimit = 1
+	if _, err := finder.FindDeals(context.Background(), dealOrder()); err != nil {
+		t.Fatal(err)
+	}
+	if most > 2 {
+		t.Errorf("%d price requests ran at once, want at most 2", most)
+	}
+}
+
+func TestFindDealsCancel(t *testing.T) {
+	started := make(chan struct{}, len(dealSavings))
+	var aborted int32
+	server := jsonServer(t, func(r *http.Request) interface{} {
+		// Coupon prices hang until the request is aborted
+		if len(requestCoupons(t, r)) > 0 {
+			started <- struct{}{}
+			select {
+			case <-r.Context().Done():
+				atomic.AddInt32(&aborted, 1)
+			case <-time.After(5 * time.Second):
+			}
+		}
+		return map[string]interface{}{
+			"Order": map[string]interface{}{"AmountsBreakdown": map[string]interface{}{"Customer": 25.0}},
+		}
+	})
+	useURLs(t, func(urls *utils.URLConfig) { urls.Order.Price = server.URL })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	begin := time.Now()
+	_, err := NewDealFinder(dealMenu()).FindDeals(ctx, dealOrder())
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("FindDeals = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(begin); elapsed > 2*time.Second {
+		t.Errorf("FindDeals took %v to return after cancelling", elapsed)
+	}
+
+	// Cancelling aborts the requests already sent rather than waiting on them
+	server.Close()
+	if atomic.LoadInt32(&aborted) == 0 {
+		t.Error("no in-flight price request was aborted")
+	}
+}
+
+func TestDealCacheKey(t *testing.T) {
+	first, second := dealOrder(), dealOrder()
+	first.AddCoupon(NewCoupon("A")).AddCoupon(NewCoupon("b"))
+	second.AddCoupon(NewCoupon("B")).AddCoupon(NewCoupon("a"))
+	if dealCacheKey(first) != dealCacheKey(second) {
+		t.Error("the same coupons in another order or case have different cache keys")
+	}
+
+	second.ServiceMethod = "Carryout"
+	if dealCacheKey(first) == dealCacheKey(second) {
+		t.Error("orders for different service methods share a cache key")
+	}
+
+	third := dealOrder()
+	third.AddCoupon(NewCoupon("A")).AddCoupon(NewCoupon("B"))
+	third.Products[0].Options = map[string]interface{}{"P": map[string]interface{}{"1/1": "1"}}
+	if dealCacheKey(first) == dealCacheKey(third) {
+		t.Error("orders with different toppings share a cache key")
+	}
+}
+
+func TestCouponCombinations(t *testing.T) {
+	coupons := []*Coupon{NewCoupon("A"), NewCoupon("B"), NewCoupon("C"), NewCoupon("D")}
+
+	codes := func(combinations [][]*Coupon) []string {
+		return dealCodes(func() []*Deal {
+			deals := make([]*Deal, len(combinations))
+			for i, combination := range combinations {
+				deals[i] = &Deal{Coupons: combination}
+			}
+			return deals
+		}())
+	}
+
+	tests := []struct {
+		size int
+		want []string
+	}{
+		{0, []string{""}},
+		{1, []string{"A", "B", "C", "D"}},
+		{2, []string{"A+B", "A+C", "A+D", "B+C", "B+D", "C+D"}},
+		{3, []string{"A+B+C", "A+B+D", "A+C+D", "B+C+D"}},
+		{5, []string{}},
+	}
+	for _, tt := range tests {
+		if got := codes(couponCombinations(coupons, tt.size)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("couponCombinations(4, %d) = %v, want %v", tt.size, got, tt.want)
+		}
+	}
+}
@@ -23,8 +23,19 @@ func (df *DominosFormat) SetDominosAPIResponse(response map[string]interface{})
 
 // GetFormatted returns the struct as a map with PascalCase keys
 func (df *DominosFormat) GetFormatted() map[string]interface{} {
+	return getFormatted(df)
+}
+
+// SetFormatted updates the struct from a map with keys in any format
+func (df *DominosFormat) SetFormatted(data map[string]interface{}) {
+	setFormatted(df, data)
+}
+
+// getFormatted converts a model to a map with PascalCase keys. Models pass
+// themselves so that their own fields, not just DominosFormat's, are included.
+func getFormatted(model interface{}) map[string]interface{} {
 	// Convert the struct to a map
-	data, _ := json.Marshal(df)
+	data, _ := json.Marshal(model)
 	var objMap map[string]interface{}
 	json.Unmarshal(data, &objMap)
 
@@ -32,18 +43,72 @@ func (df *DominosFormat) GetFormatted() map[string]interface{} {
 	delete(objMap, "dominosAPIResponse")
 
 	// Convert to Pascal case
-	pascalMap, _ := utils.PascalObjectKeys(objMap).(map[string]interface{})
+	pascalMap, _ := convertKeys(objMap, pascalKey).(map[string]interface{})
 	return pascalMap
 }
 
-// SetFormatted updates the struct from a map with keys in any format
-func (df *DominosFormat) SetFormatted(data map[string]interface{}) {
+// setFormatted updates a model from a map with keys in any format
+func setFormatted(model interface{}, data map[string]interface{}) {
 	// Convert the keys to camelCase
-	camelMap, _ := utils.CamelObjectKeys(data).(map[string]interface{})
+	camelMap, _ := convertKeys(data, camelKey).(map[string]interface{})
 
 	// Convert map to JSON
 	jsonData, _ := json.Marshal(camelMap)
 
 	// Unmarshal JSON into the struct
-	json.Unmarshal(jsonData, df)
+	json.Unmarshal(jsonData, model)
+}
+
+// setFormattedShallow is like setFormatted but only converts the top-level keys,
+// for models whose nested keys are data such as product codes
+func setFormattedShallow(model interface{}, data map[string]interface{}) {
+	camelMap := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		camelMap[camelKey(key)] = value
+	}
+
+	jsonData, _ := json.Marshal(camelMap)
+	json.Unmarshal(jsonData, model)
+}
+
+// Model keys Domino's writes as initialisms, which ToPascal and ToCamel would
+// turn into "Id" and "iD"
+var initialismKeys = map[string]string{"id": "ID"}
+
+// pascalKey converts a model's JSON key to the key Domino's uses
+func pascalKey(key string) string {
+	if initialism, ok := initialismKeys[key]; ok {
+		return initialism
+	}
+	return utils.ToPascal(key)
+}
+
+// camelKey converts a key from Domino's to a model's JSON key
+func camelKey(key string) string {
+	for camel, initialism := range initialismKeys {
+		if key == initialism {
+			return camel
+		}
+	}
+	return utils.ToCamel(key)
+}
+
+// convertKeys recursively renames the keys of maps, including those in slices
+func convertKeys(obj interface{}, convert func(string) string) interface{} {
+	switch value := obj.(type) {
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(value))
+		for key, nested := range value {
+			converted[convert(key)] = convertKeys(nested, convert)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(value))
+		for i, nested := range value {
+			converted[i] = convertKeys(nested, convert)
+		}
+		return converted
+	default:
+		return obj
+	}
 }
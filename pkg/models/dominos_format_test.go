@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFormattedID(t *testing.T) {
+	item := &Item{}
+	item.SetFormatted(map[string]interface{}{"Code": "14SCREEN", "ID": 2.0, "Qty": 1.0})
+	if item.ID != 2 || item.Code != "14SCREEN" {
+		t.Fatalf("SetFormatted = %+v, want ID 2", item)
+	}
+
+	formatted := item.GetFormatted()
+	if formatted["ID"] != 2.0 {
+		t.Errorf("GetFormatted = %v, want an ID key", formatted)
+	}
+	if _, ok := formatted["Id"]; ok {
+		t.Errorf("GetFormatted wrote an Id key: %v", formatted)
+	}
+
+	data, err := json.Marshal(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"id":2`) {
+		t.Errorf("json.Marshal = %s, want an id key", data)
+	}
+
+	order := &Order{}
+	order.AddItem(item)
+	products, _ := order.GetFormatted()["Products"].([]interface{})
+	if len(products) != 1 || products[0].(map[string]interface{})["ID"] != 2.0 {
+		t.Errorf("order products = %v, want the item's ID", products)
+	}
+}
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// useURLs changes the active configuration for the length of a test, usually to
+// point endpoints at a stub server
+func useURLs(t *testing.T, configure func(urls *utils.URLConfig)) {
+	t.Helper()
+
+	saved := utils.URLs
+	t.Cleanup(func() { utils.URLs = saved })
+	configure(&utils.URLs)
+}
+
+// jsonServer starts a stub server answering every request with the JSON
+// encoding of whatever respond returns
+func jsonServer(t *testing.T, respond func(r *http.Request) interface{}) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(respond(r))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// loadFixture decodes a JSON file from testdata
+func loadFixture(t *testing.T, name string) map[string]interface{} {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixture map[string]interface{}
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatal(err)
+	}
+	return fixture
+}
+
+// loadMenu reads the test menu in testdata/menu.json
+func loadMenu(t *testing.T) *Menu {
+	t.Helper()
+
+	data := loadFixture(t, "menu.json")
+	menu := &Menu{}
+	menu.SetFormatted(data)
+	menu.SetDominosAPIResponse(data)
+	return menu
+}
+
+// liveStoreID returns the store that live tests run against, set with
+// DOMINOS_LIVE_STORE, and skips the test when it isn't set
+func liveStoreID(t *testing.T) string {
+	t.Helper()
+
+	storeID := os.Getenv("DOMINOS_LIVE_STORE")
+	if storeID == "" {
+		t.Skip("set DOMINOS_LIVE_STORE to a store ID to run against Domino's")
+	}
+	return storeID
+}
+
+// recordingTransport keeps the body of every response it passes on
+type recordingTransport struct {
+	base   http.RoundTripper
+	mu     sync.Mutex
+	bodies [][]byte
+}
+
+// RoundTrip sends the request and copies the response body
+func (r *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	r.bodies = append(r.bodies, body)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// recordResponses captures the responses utils.Client receives for the length
+// of a test, and returns the last one
+func recordResponses(t *testing.T) func() []byte {
+	t.Helper()
+
+	saved := utils.Client.Transport
+	base := saved
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	recorder := &recordingTransport{base: base}
+	utils.Client.Transport = recorder
+	t.Cleanup(func() { utils.Client.Transport = saved })
+
+	return func() []byte {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+
+		if len(recorder.bodies) == 0 {
+			return nil
+		}
+		return recorder.bodies[len(recorder.bodies)-1]
+	}
+}
+
+// saveFixture writes a live response over a testdata fixture when
+// DOMINOS_RECORD is set, so fixtures can be refreshed from real responses
+func saveFixture(t *testing.T, name string, body []byte) {
+	t.Helper()
+
+	if os.Getenv("DOMINOS_RECORD") == "" {
+		return
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		t.Fatalf("response for %s isn't JSON: %v", name, err)
+	}
+	indented.WriteByte('\n')
+	if err := os.WriteFile("testdata/"+name, indented.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
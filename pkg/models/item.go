@@ -36,3 +36,13 @@ func NewItem(itemData map[string]interface{}) (*Item, error) {
 
 	return item, nil
 }
+
+// GetFormatted returns the item as a map with PascalCase keys
+func (i *Item) GetFormatted() map[string]interface{} {
+	return getFormatted(i)
+}
+
+// SetFormatted updates the item from a map with keys in any format
+func (i *Item) SetFormatted(data map[string]interface{}) {
+	setFormatted(i, data)
+}
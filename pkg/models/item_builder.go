@@ -0,0 +1,490 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// Portions of a pizza a topping can cover
+const (
+	PortionWhole = "1/1"
+	PortionLeft  = "1/2"
+	PortionRight = "2/2"
+)
+
+// Letters folded when matching topping names, so "jalapeños" finds "Jalapeno"
+var toppingNameFolder = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ñ", "n", "ü", "u")
+
+// ItemBuilder builds an item from a specialty product such as S_ZZ or a
+// preconfigured product, starting from its default toppings so they don't have
+// to be copied by hand, and tracking how the item differs from that recipe
+type ItemBuilder struct {
+	menu        *Menu
+	variantCode string
+	productType string
+	qty         int
+	// Amounts by topping code and portion, in the recipe and now
+	recipe  map[string]map[string]string
+	options map[string]map[string]string
+	// Amounts each topping can have, from the product's AvailableToppings
+	available    map[string][]string
+	instructions []string
+	err          error
+}
+
+// ItemChange is a topping that differs from the recipe. Default and Current are
+// amounts by portion, and are empty when the topping is missing.
+type ItemChange struct {
+	Code    string
+	Name    string
+	Default map[string]string
+	Current map[string]string
+}
+
+// Added reports whether the topping isn't in the recipe
+func (c ItemChange) Added() bool {
+	return len(c.Default) == 0
+}
+
+// Removed reports whether a topping in the recipe was taken off
+func (c ItemChange) Removed() bool {
+	return len(c.Current) == 0
+}
+
+// NewItemBuilder starts an item from a menu code. The code may be a preconfigured
+// product such as "14SCDELUX", a variant such as "14SCEXTRAV", or a product such
+// as "S_ZZ" with a size code or name such as "14" or "large"; size is ignored
+// otherwise unless it contradicts the code.
+func (m *Menu) NewItemBuilder(code string, size string) (*ItemBuilder, error) {
+	b := &ItemBuilder{
+		menu:      m,
+		qty:       1,
+		recipe:    make(map[string]map[string]string),
+		available: make(map[string][]string),
+	}
+
+	var product map[string]interface{}
+	if preconfigured, ok := m.PreconfiguredProducts[code].(map[string]interface{}); ok {
+		b.variantCode = code
+		b.recipe = parseOptions(menuText(lookupKey(preconfigured, "Options")))
+		if referenced, ok := lookupKey(preconfigured, "ReferencedProductCode").(string); ok {
+			product, _ = m.GetProduct(referenced)
+		}
+	}
+	if variant, ok := m.GetVariant(code); ok {
+		b.variantCode = code
+		if productCode, ok := variant["ProductCode"].(string); ok {
+			product, _ = m.GetProduct(productCode)
+		}
+	}
+	if b.variantCode == "" {
+		var ok bool
+		if product, ok = m.GetProduct(code); !ok {
+			return nil, utils.NewDominosProductsError("Product " + code + " is not on the menu")
+		}
+		variantCode, err := m.variantForSize(product, size)
+		if err != nil {
+			return nil, err
+		}
+		b.variantCode = variantCode
+	} else if _, isVariant := m.Variants[b.variantCode]; isVariant && size != "" && !m.variantHasSize(b.variantCode, size) {
+		return nil, utils.NewDominosProductsError(code + " is not size " + size)
+	}
+
+	if product != nil {
+		b.productType, _ = product["ProductType"].(string)
+		if len(b.recipe) == 0 {
+			b.recipe = parseOptions(menuText(product["DefaultToppings"]))
+			for code, portions := range parseOptions(menuText(product["DefaultSides"])) {
+				b.recipe[code] = portions
+			}
+		}
+		for _, key := range []string{"AvailableToppings", "AvailableSides"} {
+			for _, option := range strings.Split(menuText(product[key]), ",") {
+				code, amounts, _ := strings.Cut(option, "=")
+				if code = strings.TrimSpace(code); code != "" {
+					b.available[code] = strings.Split(amounts, ":")
+				}
+			}
+		}
+	}
+
+	b.options = copyOptions(b.recipe)
+	return b, nil
+}
+
+// Qty sets how many of the item to order
+func (b *ItemBuilder) Qty(qty int) *ItemBuilder {
+	if qty < 1 {
+		b.fail(utils.NewDominosValidationError("Item quantity must be at least 1"))
+		return b
+	}
+	b.qty = qty
+	return b
+}
+
+// Add puts a normal amount of a topping, given by code or name, on the whole item
+func (b *ItemBuilder) Add(topping string) *ItemBuilder {
+	return b.Set(topping, PortionWhole, "1")
+}
+
+// Remove takes a topping off the item. Toppings in the recipe are kept in the
+// options with an amount of "0", since Domino's puts back any it isn't sent.
+func (b *ItemBuilder) Remove(topping string) *ItemBuilder {
+	code, ok := b.toppingCode(topping)
+	if !ok {
+		return b
+	}
+	b.remove(code)
+	return b
+}
+
+// Set puts an amount such as "0.5", "1" or "1.5" of a topping on a portion of
+// the item. Setting the whole item replaces both halves, and setting a half of
+// a topping on the whole item leaves the whole amount on the other half.
+func (b *ItemBuilder) Set(topping string, portion string, amount string) *ItemBuilder {
+	code, ok := b.toppingCode(topping)
+	if !ok {
+		return b
+	}
+
+	if portion != PortionWhole && portion != PortionLeft && portion != PortionRight {
+		b.fail(utils.NewDominosValidationError("Portion must be " + PortionWhole + ", " + PortionLeft + " or " + PortionRight))
+		return b
+	}
+	if amounts := b.available[code]; len(amounts) > 0 && amounts[0] != "" && !containsFold(amounts, amount) {
+		b.fail(utils.NewDominosValidationError("Amount " + amount + " of " + code + " must be one of " + strings.Join(amounts, ", ")))
+		return b
+	}
+
+	portions := b.options[code]
+	if portion == PortionWhole || isRemoved(portions) {
+		portions = make(map[string]string)
+	}
+	if whole, ok := portions[PortionWhole]; ok {
+		delete(portions, PortionWhole)
+		portions[PortionLeft], portions[PortionRight] = whole, whole
+	}
+	delete(portions, portion)
+	if amount != "0" {
+		portions[portion] = amount
+	}
+
+	switch {
+	case len(portions) == 0:
+		b.remove(code)
+	case len(portions) == 2 && portions[PortionLeft] == portions[PortionRight]:
+		b.options[code] = map[string]string{PortionWhole: portions[PortionLeft]}
+	default:
+		b.options[code] = portions
+	}
+	return b
+}
+
+// remove takes a topping off, sending "0" for a topping in the recipe
+func (b *ItemBuilder) remove(code string) {
+	if _, inRecipe := b.recipe[code]; inRecipe {
+		b.options[code] = map[string]string{PortionWhole: "0"}
+		return
+	}
+	delete(b.options, code)
+}
+
+// Reset puts a topping back to its amount in the recipe
+func (b *ItemBuilder) Reset(topping string) *ItemBuilder {
+	code, ok := b.toppingCode(topping)
+	if !ok {
+		return b
+	}
+
+	delete(b.options, code)
+	if portions, ok := b.recipe[code]; ok {
+		b.options[code] = copyPortions(portions)
+	}
+	return b
+}
+
+// CookingInstruction adds a cooking instruction, such as "well done" or
+// CookingSquareCut, which Build checks against the menu
+func (b *ItemBuilder) CookingInstruction(instruction string) *ItemBuilder {
+	b.instructions = append(b.instructions, instruction)
+	return b
+}
+
+// Changes returns the toppings that differ from the recipe, sorted by code
+func (b *ItemBuilder) Changes() []ItemChange {
+	codes := make(map[string]bool)
+	for code := range b.recipe {
+		codes[code] = true
+	}
+	for code := range b.options {
+		codes[code] = true
+	}
+
+	changes := make([]ItemChange, 0)
+	for _, code := range sortedKeys(codes) {
+		current := b.options[code]
+		if isRemoved(current) {
+			current = nil
+		}
+		if equalPortions(b.recipe[code], current) {
+			continue
+		}
+		changes = append(changes, ItemChange{
+			Code:    code,
+			Name:    b.toppingName(code),
+			Default: copyPortions(b.recipe[code]),
+			Current: copyPortions(current),
+		})
+	}
+	return changes
+}
+
+// Build returns the item, or the first error from building it. Its options
+// list every topping on it, including the recipe's.
+func (b *ItemBuilder) Build() (*Item, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	item, err := NewItem(map[string]interface{}{"code": b.variantCode, "qty": b.qty})
+	if err != nil {
+		return nil, err
+	}
+	for code, portions := range b.options {
+		options := make(map[string]interface{}, len(portions))
+		for portion, amount := range portions {
+			options[portion] = amount
+		}
+		item.Options[code] = options
+	}
+	for _, instruction := range b.instructions {
+		if err := item.AddCookingInstruction(b.menu, instruction); err != nil {
+			return nil, err
+		}
+	}
+	return item, nil
+}
+
+// fail keeps the first error for Build
+func (b *ItemBuilder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// toppingCode resolves a topping code or name, failing the build if the product
+// can't have it
+func (b *ItemBuilder) toppingCode(topping string) (string, bool) {
+	codes := b.menu.findToppings(b.productType, topping)
+	if len(codes) == 0 {
+		b.fail(utils.NewDominosProductsError("Topping " + topping + " is not on the menu"))
+		return "", false
+	}
+	if len(codes) > 1 {
+		names := make([]string, 0, len(codes))
+		for _, code := range codes {
+			names = append(names, b.toppingName(code))
+		}
+		b.fail(utils.NewDominosProductsError("Topping " + topping + " could be any of " + strings.Join(names, ", ")))
+		return "", false
+	}
+	code := codes[0]
+
+	_, available := b.available[code]
+	_, inRecipe := b.recipe[code]
+	if len(b.available) > 0 && !available && !inRecipe {
+		b.fail(utils.NewDominosProductsError(b.toppingName(code) + " is not available on " + b.variantCode))
+		return "", false
+	}
+	return code, true
+}
+
+// toppingName returns the menu name of a topping code, or the code
+func (b *ItemBuilder) toppingName(code string) string {
+	for _, section := range []map[string]interface{}{b.menu.Toppings, b.menu.Sides} {
+		if options, ok := section[b.productType].(map[string]interface{}); ok {
+			if data, ok := options[code].(map[string]interface{}); ok {
+				if name, ok := data["Name"].(string); ok {
+					return name
+				}
+			}
+		}
+	}
+	return code
+}
+
+// findToppings finds a topping or side of a product type by code, or by the
+// words of its name such as "onions" or "jalapeños". Several are returned when
+// the words are in more than one name, as "peppers" is.
+func (m *Menu) findToppings(productType string, topping string) []string {
+	options := make(map[string]interface{})
+	for _, section := range []map[string]interface{}{m.Toppings, m.Sides} {
+		if entries, ok := section[productType].(map[string]interface{}); ok {
+			for code, data := range entries {
+				options[code] = data
+			}
+		}
+	}
+
+	for code := range options {
+		if strings.EqualFold(code, topping) {
+			return []string{code}
+		}
+	}
+
+	words := toppingNameWords(topping)
+	matches := make([]string, 0)
+	if len(words) == 0 {
+		return matches
+	}
+	for _, code := range sortedKeys(options) {
+		data, _ := options[code].(map[string]interface{})
+		name := toppingNameWords(menuText(data["Name"]))
+		if strings.Join(name, " ") == strings.Join(words, " ") {
+			return []string{code}
+		}
+		if containsTokens(name, words) {
+			matches = append(matches, code)
+		}
+	}
+	return matches
+}
+
+// variantForSize returns the product's variant in a size, preferring the
+// first listed when several crusts come in that size
+func (m *Menu) variantForSize(product map[string]interface{}, size string) (string, error) {
+	code, _ := product["Code"].(string)
+	variants := stringList(product["Variants"])
+	if size == "" {
+		if len(variants) == 1 {
+			return variants[0], nil
+		}
+		return "", utils.NewDominosProductsError("A size is required for " + code)
+	}
+
+	for _, variantCode := range variants {
+		if m.variantHasSize(variantCode, size) {
+			return variantCode, nil
+		}
+	}
+	return "", utils.NewDominosProductsError(code + " does not come in size " + size)
+}
+
+// variantHasSize reports whether a variant's size has the code or name given.
+// A name matches its words, so "large" matches "Large (14\")" but not "X-Large".
+func (m *Menu) variantHasSize(variantCode string, size string) bool {
+	variant, ok := m.GetVariant(variantCode)
+	if !ok {
+		return false
+	}
+
+	sizeCode, _ := variant["SizeCode"].(string)
+	if strings.EqualFold(sizeCode, size) {
+		return true
+	}
+
+	productType := ""
+	if product, ok := m.GetProduct(menuText(variant["ProductCode"])); ok {
+		productType, _ = product["ProductType"].(string)
+	}
+	sizes, _ := m.Sizes[productType].(map[string]interface{})
+	data, _ := sizes[sizeCode].(map[string]interface{})
+	name := cleanWords(menuText(data["Name"]))
+	words := cleanWords(size)
+	if len(words) == 0 || len(name) < len(words) {
+		return false
+	}
+
+	// The name starts with the words, ignoring a size in inches
+	for i, word := range words {
+		if name[i] != word {
+			return false
+		}
+	}
+	return len(name) == len(words) || strings.Trim(name[len(words)], "0123456789") == ""
+}
+
+// parseOptions reads an option list such as "X=1,C=1.5,P=1/2=1" into amounts by
+// topping code and portion. Options without a portion cover the whole item.
+func parseOptions(options string) map[string]map[string]string {
+	parsed := make(map[string]map[string]string)
+	for _, option := range strings.Split(options, ",") {
+		parts := strings.Split(strings.TrimSpace(option), "=")
+		code := strings.TrimSpace(parts[0])
+		if code == "" {
+			continue
+		}
+
+		portion, amount := PortionWhole, "1"
+		switch len(parts) {
+		case 2:
+			amount = strings.TrimSpace(parts[1])
+		case 3:
+			portion, amount = strings.TrimSpace(parts[1]), strings.TrimSpace(parts[2])
+		}
+		if amount == "0" {
+			continue
+		}
+
+		if parsed[code] == nil {
+			parsed[code] = make(map[string]string)
+		}
+		parsed[code][portion] = amount
+	}
+	return parsed
+}
+
+// toppingNameWords returns the singular words of a topping name without accents
+func toppingNameWords(name string) []string {
+	words := cleanWords(toppingNameFolder.Replace(strings.ToLower(name)))
+	for i, word := range words {
+		words[i] = stemWord(word)
+	}
+	return words
+}
+
+// copyOptions copies amounts by topping code and portion
+func copyOptions(options map[string]map[string]string) map[string]map[string]string {
+	copied := make(map[string]map[string]string, len(options))
+	for code, portions := range options {
+		copied[code] = copyPortions(portions)
+	}
+	return copied
+}
+
+// copyPortions copies amounts by portion, returning nil for none
+func copyPortions(portions map[string]string) map[string]string {
+	if len(portions) == 0 {
+		return nil
+	}
+	copied := make(map[string]string, len(portions))
+	for portion, amount := range portions {
+		copied[portion] = amount
+	}
+	return copied
+}
+
+// isRemoved reports whether a topping's amounts take it off the item
+func isRemoved(portions map[string]string) bool {
+	for _, amount := range portions {
+		if amount != "0" {
+			return false
+		}
+	}
+	return true
+}
+
+// equalPortions reports whether two topping amounts are the same
+func equalPortions(a map[string]string, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for portion, amount := range a {
+		if b[portion] != amount {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestItemBuilder(t *testing.T) {
+	menu := loadMenu(t)
+
+	tests := []struct {
+		name  string
+		code  string
+		size  string
+		build func(b *ItemBuilder)
+		want  map[string]interface{}
+	}{
+		{
+			name:  "recipe",
+			code:  "S_PIZUH",
+			size:  "large",
+			build: func(b *ItemBuilder) {},
+			want: map[string]interface{}{
+				"X": map[string]interface{}{"1/1": "1"},
+				"C": map[string]interface{}{"1/1": "1"},
+				"H": map[string]interface{}{"1/1": "1"},
+				"N": map[string]interface{}{"1/1": "1"},
+			},
+		},
+		{
+			name: "remove sends zero",
+			code: "14SCUH",
+			build: func(b *ItemBuilder) {
+				b.Remove("pineapple").Add("mushrooms")
+			},
+			want: map[string]interface{}{
+				"X": map[string]interface{}{"1/1": "1"},
+				"C": map[string]interface{}{"1/1": "1"},
+				"H": map[string]interface{}{"1/1": "1"},
+				"N": map[string]interface{}{"1/1": "0"},
+				"M": map[string]interface{}{"1/1": "1"},
+			},
+		},
+		{
+			name: "half of a whole topping",
+			code: "14SCUH",
+			build: func(b *ItemBuilder) {
+				b.Set("C", PortionLeft, "1.5").Set("ham", PortionRight, "0")
+			},
+			want: map[string]interface{}{
+				"X": map[string]interface{}{"1/1": "1"},
+				"C": map[string]interface{}{"1/2": "1.5", "2/2": "1"},
+				"H": map[string]interface{}{"1/2": "1"},
+				"N": map[string]interface{}{"1/1": "1"},
+			},
+		},
+		{
+			name: "halves set back to the same amount",
+			code: "14SCUH",
+			build: func(b *ItemBuilder) {
+				b.Set("C", PortionLeft, "0").Set("C", PortionLeft, "1").Remove("P")
+			},
+			want: map[string]interface{}{
+				"X": map[string]interface{}{"1/1": "1"},
+				"C": map[string]interface{}{"1/1": "1"},
+				"H": map[string]interface{}{"1/1": "1"},
+				"N": map[string]interface{}{"1/1": "1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder, err := menu.NewItemBuilder(tt.code, tt.size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.build(builder)
+
+			item, err := builder.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if item.Code != "14SCUH" {
+				t.Errorf("Code = %s, want 14SCUH", item.Code)
+			}
+			if !reflect.DeepEqual(item.Options, tt.want) {
+				t.Errorf("Options = %v, want %v", item.Options, tt.want)
+			}
+		})
+	}
+}
+
+func TestItemBuilderChanges(t *testing.T) {
+	builder, err := loadMenu(t).NewItemBuilder("14SCUH", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder.Remove("pineapple").Set("C", PortionLeft, "1.5").Add("mushrooms")
+
+	changes := builder.Changes()
+	if len(changes) != 3 {
+		t.Fatalf("Changes = %+v, want cheese, mushrooms and pineapple", changes)
+	}
+	for _, change := range changes {
+		switch change.Code {
+		case "C":
+			if change.Added() || change.Removed() || change.Current[PortionRight] != "1" {
+				t.Errorf("cheese = %+v, want the right half kept", change)
+			}
+		case "M":
+			if !change.Added() {
+				t.Errorf("mushrooms = %+v, want added", change)
+			}
+		case "N":
+			if !change.Removed() || change.Name != "Pineapple" {
+				t.Errorf("pineapple = %+v, want removed", change)
+			}
+		default:
+			t.Errorf("unexpected change %+v", change)
+		}
+	}
+
+	builder.Reset("pineapple")
+	if len(builder.Changes()) != 2 {
+		t.Errorf("Reset left %+v", builder.Changes())
+	}
+}
+
+func TestItemBuilderErrors(t *testing.T) {
+	menu := loadMenu(t)
+
+	tests := []struct {
+		name  string
+		build func(b *ItemBuilder)
+		want  string
+	}{
+		{"ambiguous name", func(b *ItemBuilder) { b.Add("peppers") }, "Green Peppers, Banana Peppers"},
+		{"unknown topping", func(b *ItemBuilder) { b.Add("anchovies") }, "not on the menu"},
+		{"unavailable amount", func(b *ItemBuilder) { b.Set("C", PortionWhole, "2") }, "2"},
+		{"quantity", func(b *ItemBuilder) { b.Qty(0) }, "at least 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder, err := menu.NewItemBuilder("S_PIZZA", "14")
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.build(builder)
+			if _, err := builder.Build(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Build error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := menu.NewItemBuilder("14SCUH", "12"); err == nil {
+		t.Error("NewItemBuilder accepted a size that contradicts the variant")
+	}
+}
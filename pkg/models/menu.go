@@ -2,6 +2,8 @@ package models
 
 import (
 	"strings"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
 )
 
 // Menu represents a Domino's Pizza menu
@@ -24,6 +26,9 @@ type Menu struct {
 	ExcludedOptions          map[string]interface{} `json:"excludedOptions"`
 	CookingInstructions      map[string]interface{} `json:"cookingInstructions"`
 	CookingInstructionGroups map[string]interface{} `json:"cookingInstructionGroups"`
+	// Currency of the menu's prices, set by Store.GetMenu from the market the
+	// menu was fetched in. Menus without one are priced in the current market's.
+	Currency string `json:"currency"`
 }
 
 // ProductCategory represents a category of products like Pizzas, Sides, Drinks, etc.
@@ -92,6 +97,41 @@ func (m *Menu) GetVariant(variantCode string) (map[string]interface{}, bool) {
 	return nil, false
 }
 
+// GetVariantPrice returns the price of a variant in the menu's currency
+func (m *Menu) GetVariantPrice(variantCode string) (Money, bool) {
+	variant, ok := m.GetVariant(variantCode)
+	if !ok {
+		return Money{}, false
+	}
+
+	price, err := menuPrice(variant["Price"], m.currency())
+	if err != nil {
+		return Money{}, false
+	}
+
+	return price, true
+}
+
+// currency returns the currency of the menu's prices. m may be nil.
+func (m *Menu) currency() string {
+	if m != nil && m.Currency != "" {
+		return m.Currency
+	}
+	return utils.CurrentMarket().Currency
+}
+
+// menuPrice converts a price, usually a string like "13.99", to Money
+func menuPrice(value interface{}, currency string) (Money, error) {
+	switch price := value.(type) {
+	case string:
+		return ParseMoney(price, currency)
+	case float64:
+		return MoneyFromFloat(price, currency), nil
+	}
+
+	return Money{}, utils.NewDominosCurrencyError("Menu price is missing")
+}
+
 // GetMenuCategories returns all food categories from the menu
 func (m *Menu) GetMenuCategories() map[string]interface{} {
 	// Get the raw response
@@ -254,3 +294,14 @@ func (m *Menu) GetDrinks() map[string]map[string]interface{} {
 
 	return result
 }
+
+// GetFormatted returns the menu as a map with PascalCase keys
+func (m *Menu) GetFormatted() map[string]interface{} {
+	return getFormatted(m)
+}
+
+// SetFormatted updates the menu from a map with keys in any format.
+// Nested keys such as product codes are left untouched.
+func (m *Menu) SetFormatted(data map[string]interface{}) {
+	setFormattedShallow(m, data)
+}
@@ -0,0 +1,230 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// DefaultMenuFetchConcurrency is the most menus a MenuComparer fetches at once
+const DefaultMenuFetchConcurrency = 4
+
+// MenuComparer fetches the menus of several stores with a bounded number of
+// requests at once and compares their variant prices
+type MenuComparer struct {
+	// Concurrency is the most menu requests made at once
+	Concurrency int
+	// Language of the menus, the configured language if empty
+	Language string
+}
+
+// PriceComparison is the price of every variant at every store whose menu was
+// fetched, with the stores that failed
+type PriceComparison struct {
+	// StoreIDs are the stores compared, in the order given
+	StoreIDs []string
+	// Variants are sorted by code
+	Variants []*VariantPrices
+	Failures []StoreFailure
+}
+
+// VariantPrices is a variant's price at each store that sells it
+type VariantPrices struct {
+	Code        string
+	ProductCode string
+	Name        string
+	// Prices by store ID; stores that don't sell the variant are missing
+	Prices map[string]Money
+	Min    Money
+	Max    Money
+	// Median is the mean of the middle two prices when there is an even number,
+	// rounded to the nearest minor unit
+	Median Money
+	// CheapestStoreIDs and PriciestStoreIDs are the stores charging Min and Max
+	CheapestStoreIDs []string
+	PriciestStoreIDs []string
+}
+
+// StoreFailure is a store whose menu couldn't be fetched
+type StoreFailure struct {
+	StoreID string
+	Err     error
+}
+
+// NewMenuComparer creates a menu comparer
+func NewMenuComparer() *MenuComparer {
+	return &MenuComparer{Concurrency: DefaultMenuFetchConcurrency}
+}
+
+// CompareNearby compares the menus of stores found by NewNearbyStores
+func (c *MenuComparer) CompareNearby(ctx context.Context, nearby *NearbyStores) (*PriceComparison, error) {
+	storeIDs := make([]string, 0, len(nearby.Stores))
+	for _, store := range nearby.Stores {
+		storeIDs = append(storeIDs, store.StoreID)
+	}
+	return c.Compare(ctx, storeIDs)
+}
+
+// Compare fetches the stores' menus and compares their prices. Stores whose menu
+// can't be fetched are listed in Failures; an error is only returned if ctx is
+// done or no menu could be fetched.
+func (c *MenuComparer) Compare(ctx context.Context, storeIDs []string) (*PriceComparison, error) {
+	storeIDs = uniqueStoreIDs(storeIDs)
+	if len(storeIDs) == 0 {
+		return nil, utils.NewDominosStoreError("At least one store ID is required to compare menus")
+	}
+
+	menus, errs, err := c.fetchMenus(ctx, storeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &PriceComparison{
+		StoreIDs: make([]string, 0, len(storeIDs)),
+		Variants: make([]*VariantPrices, 0),
+		Failures: make([]StoreFailure, 0),
+	}
+	variants := make(map[string]*VariantPrices)
+	for i, storeID := range storeIDs {
+		if errs[i] != nil {
+			comparison.Failures = append(comparison.Failures, StoreFailure{StoreID: storeID, Err: errs[i]})
+			continue
+		}
+		comparison.StoreIDs = append(comparison.StoreIDs, storeID)
+
+		menu := menus[i]
+		for code := range menu.Variants {
+			price, ok := menu.GetVariantPrice(code)
+			if !ok {
+				continue
+			}
+
+			prices, ok := variants[code]
+			if !ok {
+				variant, _ := menu.GetVariant(code)
+				prices = &VariantPrices{Code: code, Prices: make(map[string]Money)}
+				prices.ProductCode, _ = variant["ProductCode"].(string)
+				prices.Name, _ = variant["Name"].(string)
+				variants[code] = prices
+				comparison.Variants = append(comparison.Variants, prices)
+			}
+			prices.Prices[storeID] = price
+		}
+	}
+
+	if len(comparison.StoreIDs) == 0 {
+		return comparison, utils.NewDominosStoreError("No store menus could be fetched")
+	}
+
+	for _, prices := range comparison.Variants {
+		prices.summarize(comparison.StoreIDs)
+	}
+	sort.Slice(comparison.Variants, func(i, j int) bool {
+		return comparison.Variants[i].Code < comparison.Variants[j].Code
+	})
+
+	return comparison, nil
+}
+
+// fetchMenus fetches each store's menu with at most Concurrency requests at
+// once, returning the menus and errors by position
+func (c *MenuComparer) fetchMenus(ctx context.Context, storeIDs []string) ([]*Menu, []error, error) {
+	concurrency := c.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	menus := make([]*Menu, len(storeIDs))
+	errs := make([]error, len(storeIDs))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, storeID := range storeIDs {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, nil, ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(i int, storeID string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			store := &Store{StoreID: storeID}
+			menus[i], errs[i] = store.GetMenuContext(ctx, c.Language)
+		}(i, storeID)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	return menus, errs, nil
+}
+
+// Variant returns the prices of a variant
+func (p *PriceComparison) Variant(code string) (*VariantPrices, bool) {
+	for _, prices := range p.Variants {
+		if prices.Code == code {
+			return prices, true
+		}
+	}
+	return nil, false
+}
+
+// Spread returns the difference between the highest and lowest price
+func (v *VariantPrices) Spread() Money {
+	spread, _ := v.Max.Sub(v.Min)
+	return spread
+}
+
+// summarize sets the min, max and median prices, listing stores in the
+// comparison's order
+func (v *VariantPrices) summarize(storeIDs []string) {
+	prices := make([]Money, 0, len(v.Prices))
+	for _, price := range v.Prices {
+		prices = append(prices, price)
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Amount < prices[j].Amount })
+
+	v.Min, v.Max = prices[0], prices[len(prices)-1]
+	middle := len(prices) / 2
+	v.Median = prices[middle]
+	if len(prices)%2 == 0 {
+		// Halves of the minor unit round away from zero
+		sum := prices[middle-1].Amount + prices[middle].Amount
+		v.Median.Amount = sum/2 + sum%2
+	}
+
+	v.CheapestStoreIDs, v.PriciestStoreIDs = nil, nil
+	for _, storeID := range storeIDs {
+		price, ok := v.Prices[storeID]
+		if !ok {
+			continue
+		}
+		if price.Amount == v.Min.Amount {
+			v.CheapestStoreIDs = append(v.CheapestStoreIDs, storeID)
+		}
+		if price.Amount == v.Max.Amount {
+			v.PriciestStoreIDs = append(v.PriciestStoreIDs, storeID)
+		}
+	}
+}
+
+// uniqueStoreIDs drops empty and repeated store IDs, keeping the order
+func uniqueStoreIDs(storeIDs []string) []string {
+	seen := make(map[string]bool, len(storeIDs))
+	unique := make([]string, 0, len(storeIDs))
+	for _, storeID := range storeIDs {
+		if storeID == "" || seen[storeID] {
+			continue
+		}
+		seen[storeID] = true
+		unique = append(unique, storeID)
+	}
+	return unique
+}
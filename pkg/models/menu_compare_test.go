@@ -0,0 +1,135 @@
+package models
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// menuServer serves the test menu for every store, with the price of 14SCREEN
+// set per store; stores without a price fail
+func menuServer(t *testing.T, prices map[string]string, handle func(storeID string)) *httptest.Server {
+	t.Helper()
+
+	fixture, err := os.ReadFile("testdata/menu.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		storeID := strings.TrimPrefix(r.URL.Path, "/store/")
+		if handle != nil {
+			handle(storeID)
+		}
+		price, ok := prices[storeID]
+		if !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(strings.Replace(string(fixture), `"Price": "15.99"`, `"Price": "`+price+`"`, 1)))
+	}))
+	t.Cleanup(server.Close)
+
+	useURLs(t, func(urls *utils.URLConfig) {
+		urls.Store.Menu = server.URL + "/store/${storeID}?lang=${lang}"
+	})
+	return server
+}
+
+func TestMenuComparer(t *testing.T) {
+	menuServer(t, map[string]string{"1": "15.99", "2": "16.00", "3": "15.99", "4": "17.00"}, nil)
+
+	comparison, err := NewMenuComparer().Compare(context.Background(), []string{"1", "2", "", "3", "2", "4", "5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(comparison.StoreIDs, ",") != "1,2,3,4" {
+		t.Errorf("StoreIDs = %v", comparison.StoreIDs)
+	}
+	if len(comparison.Failures) != 1 || comparison.Failures[0].StoreID != "5" {
+		t.Errorf("Failures = %+v, want store 5", comparison.Failures)
+	}
+
+	prices, ok := comparison.Variant("14SCREEN")
+	if !ok {
+		t.Fatal("14SCREEN not compared")
+	}
+	if prices.Min != NewMoney(1599, "USD") || prices.Max != NewMoney(1700, "USD") || prices.Spread() != NewMoney(101, "USD") {
+		t.Errorf("min %v, max %v, spread %v", prices.Min, prices.Max, prices.Spread())
+	}
+	// The middle two are 15.99 and 16.00, whose mean rounds up to 16.00
+	if prices.Median != NewMoney(1600, "USD") {
+		t.Errorf("Median = %v, want 16.00 USD", prices.Median)
+	}
+	if strings.Join(prices.CheapestStoreIDs, ",") != "1,3" || strings.Join(prices.PriciestStoreIDs, ",") != "4" {
+		t.Errorf("cheapest %v, priciest %v", prices.CheapestStoreIDs, prices.PriciestStoreIDs)
+	}
+}
+
+func TestMenuComparerConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	running, most := 0, 0
+	prices := make(map[string]string)
+	storeIDs := make([]string, 0)
+	for _, storeID := range strings.Split("1 2 3 4 5 6 7 8", " ") {
+		prices[storeID] = "15.99"
+		storeIDs = append(storeIDs, storeID)
+	}
+	menuServer(t, prices, func(string) {
+		mu.Lock()
+		running++
+		if running > most {
+			most = running
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+	})
+
+	comparer := NewMenuComparer()
+	comparer.Concurrency = 2
+	if _, err := comparer.Compare(context.Background(), storeIDs); err != nil {
+		t.Fatal(err)
+	}
+	if most > 2 {
+		t.Errorf("%d menus were fetched at once, want at most 2", most)
+	}
+}
+
+func TestMenuComparerCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	defer close(release)
+
+	menuServer(t, map[string]string{"1": "15.99"}, func(string) {
+		cancel()
+		<-release
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := NewMenuComparer().Compare(ctx, []string{"1"})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Compare = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Compare didn't stop the menu request when ctx was cancelled")
+	}
+}
@@ -0,0 +1,328 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// MenuDiff is what changed from one menu to another, such as a store's menu a
+// week apart or the menus of two stores. It encodes to JSON as is, and String
+// describes it for people.
+type MenuDiff struct {
+	AddedProducts   []MenuProductChange `json:"addedProducts"`
+	RemovedProducts []MenuProductChange `json:"removedProducts"`
+	AddedVariants   []MenuItemChange    `json:"addedVariants"`
+	RemovedVariants []MenuItemChange    `json:"removedVariants"`
+	PriceChanges    []MenuPriceChange   `json:"priceChanges"`
+	// ToppingChanges are toppings added to or removed from the menu, or made
+	// available or unavailable on a product
+	ToppingChanges     []MenuToppingChange `json:"toppingChanges"`
+	AddedCoupons       []MenuItemChange    `json:"addedCoupons"`
+	RemovedCoupons     []MenuItemChange    `json:"removedCoupons"`
+	CouponPriceChanges []MenuPriceChange   `json:"couponPriceChanges"`
+}
+
+// MenuProductChange is a product added to or removed from a menu
+type MenuProductChange struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	ProductType string `json:"productType"`
+}
+
+// MenuItemChange is a variant or coupon added to or removed from a menu
+type MenuItemChange struct {
+	Code        string `json:"code"`
+	ProductCode string `json:"productCode,omitempty"`
+	Name        string `json:"name"`
+	Price       Money  `json:"price"`
+}
+
+// MenuPriceChange is a variant or coupon whose price changed
+type MenuPriceChange struct {
+	Code        string `json:"code"`
+	ProductCode string `json:"productCode,omitempty"`
+	Name        string `json:"name"`
+	OldPrice    Money  `json:"oldPrice"`
+	NewPrice    Money  `json:"newPrice"`
+	// Change is NewPrice - OldPrice, or zero if the menus' currencies differ
+	Change Money `json:"change"`
+}
+
+// MenuToppingChange is a topping whose availability changed. ProductCode is
+// empty when the topping was added to or removed from the whole menu.
+type MenuToppingChange struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Category    string `json:"category"`
+	ProductCode string `json:"productCode,omitempty"`
+	Available   bool   `json:"available"`
+}
+
+// DiffMenus compares two menus, reporting what changed from old to new
+func DiffMenus(old *Menu, new *Menu) *MenuDiff {
+	diff := &MenuDiff{
+		AddedProducts:      make([]MenuProductChange, 0),
+		RemovedProducts:    make([]MenuProductChange, 0),
+		AddedVariants:      make([]MenuItemChange, 0),
+		RemovedVariants:    make([]MenuItemChange, 0),
+		PriceChanges:       make([]MenuPriceChange, 0),
+		ToppingChanges:     make([]MenuToppingChange, 0),
+		AddedCoupons:       make([]MenuItemChange, 0),
+		RemovedCoupons:     make([]MenuItemChange, 0),
+		CouponPriceChanges: make([]MenuPriceChange, 0),
+	}
+
+	diff.diffProducts(old, new)
+	diff.diffVariants(old, new)
+	diff.diffToppings(old, new)
+	diff.diffCoupons(old, new)
+
+	return diff
+}
+
+// IsEmpty reports whether the menus were the same
+func (d *MenuDiff) IsEmpty() bool {
+	return len(d.AddedProducts) == 0 && len(d.RemovedProducts) == 0 &&
+		len(d.AddedVariants) == 0 && len(d.RemovedVariants) == 0 &&
+		len(d.PriceChanges) == 0 && len(d.ToppingChanges) == 0 &&
+		len(d.AddedCoupons) == 0 && len(d.RemovedCoupons) == 0 &&
+		len(d.CouponPriceChanges) == 0
+}
+
+// String describes the changes one per line, grouped by kind
+func (d *MenuDiff) String() string {
+	if d.IsEmpty() {
+		return "No changes\n"
+	}
+
+	var b strings.Builder
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		b.WriteString(title + ":\n")
+		for _, line := range lines {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+
+	products := func(sign string, changes []MenuProductChange) []string {
+		lines := make([]string, 0, len(changes))
+		for _, c := range changes {
+			lines = append(lines, sign+" "+c.Code+" "+c.Name)
+		}
+		return lines
+	}
+	items := func(sign string, changes []MenuItemChange) []string {
+		lines := make([]string, 0, len(changes))
+		for _, c := range changes {
+			lines = append(lines, sign+" "+c.Code+" "+c.Name+" ("+c.Price.String()+")")
+		}
+		return lines
+	}
+	prices := func(changes []MenuPriceChange) []string {
+		lines := make([]string, 0, len(changes))
+		for _, c := range changes {
+			change := c.Change.String()
+			if c.Change.Amount > 0 {
+				change = "+" + change
+			}
+			lines = append(lines, c.Code+" "+c.Name+": "+c.OldPrice.String()+" -> "+c.NewPrice.String()+" ("+change+")")
+		}
+		return lines
+	}
+
+	toppings := make([]string, 0, len(d.ToppingChanges))
+	for _, c := range d.ToppingChanges {
+		sign, where := "-", "menu"
+		if c.Available {
+			sign = "+"
+		}
+		if c.ProductCode != "" {
+			where = c.ProductCode
+		}
+		toppings = append(toppings, sign+" "+c.Code+" "+c.Name+" ("+c.Category+") on "+where)
+	}
+
+	section("Products added", products("+", d.AddedProducts))
+	section("Products removed", products("-", d.RemovedProducts))
+	section("Variants added", items("+", d.AddedVariants))
+	section("Variants removed", items("-", d.RemovedVariants))
+	section("Price changes", prices(d.PriceChanges))
+	section("Toppings", toppings)
+	section("Coupons added", items("+", d.AddedCoupons))
+	section("Coupons removed", items("-", d.RemovedCoupons))
+	section("Coupon price changes", prices(d.CouponPriceChanges))
+
+	return b.String()
+}
+
+// diffProducts finds added and removed products
+func (d *MenuDiff) diffProducts(old *Menu, new *Menu) {
+	for _, code := range sortedKeys(new.Products) {
+		if _, ok := old.Products[code]; !ok {
+			d.AddedProducts = append(d.AddedProducts, menuProductChange(new, code))
+		}
+	}
+	for _, code := range sortedKeys(old.Products) {
+		if _, ok := new.Products[code]; !ok {
+			d.RemovedProducts = append(d.RemovedProducts, menuProductChange(old, code))
+		}
+	}
+}
+
+// diffVariants finds added and removed variants and price changes
+func (d *MenuDiff) diffVariants(old *Menu, new *Menu) {
+	for _, code := range sortedKeys(new.Variants) {
+		newVariant, _ := new.GetVariant(code)
+		oldVariant, ok := old.GetVariant(code)
+		if !ok {
+			d.AddedVariants = append(d.AddedVariants, menuVariantChange(new, code))
+			continue
+		}
+
+		oldPrice, oldOK := old.GetVariantPrice(code)
+		newPrice, newOK := new.GetVariantPrice(code)
+		if oldOK && newOK && oldPrice != newPrice {
+			name, _ := newVariant["Name"].(string)
+			if name == "" {
+				name, _ = oldVariant["Name"].(string)
+			}
+			productCode, _ := newVariant["ProductCode"].(string)
+			d.PriceChanges = append(d.PriceChanges, menuPriceChange(code, productCode, name, oldPrice, newPrice))
+		}
+	}
+	for _, code := range sortedKeys(old.Variants) {
+		if _, ok := new.Variants[code]; !ok {
+			d.RemovedVariants = append(d.RemovedVariants, menuVariantChange(old, code))
+		}
+	}
+}
+
+// diffToppings finds toppings added to or removed from the menu, then toppings
+// made available or unavailable on products both menus have
+func (d *MenuDiff) diffToppings(old *Menu, new *Menu) {
+	oldToppings, newToppings := menuToppings(old), menuToppings(new)
+	for key, change := range newToppings {
+		if _, ok := oldToppings[key]; !ok {
+			d.ToppingChanges = append(d.ToppingChanges, change)
+		}
+	}
+	for key, change := range oldToppings {
+		if _, ok := newToppings[key]; !ok {
+			change.Available = false
+			d.ToppingChanges = append(d.ToppingChanges, change)
+		}
+	}
+	sort.Slice(d.ToppingChanges, func(i, j int) bool {
+		a, b := d.ToppingChanges[i], d.ToppingChanges[j]
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		return a.Code < b.Code
+	})
+
+	for _, productCode := range sortedKeys(new.Products) {
+		oldProduct, ok := old.LookupProduct(productCode)
+		if !ok {
+			continue
+		}
+		newProduct, _ := new.LookupProduct(productCode)
+
+		oldCodes := availableToppings(oldProduct)
+		newCodes := availableToppings(newProduct)
+		for _, code := range sortedKeys(newCodes) {
+			if !oldCodes[code] {
+				d.ToppingChanges = append(d.ToppingChanges, productToppingChange(new, newProduct, code, true))
+			}
+		}
+		for _, code := range sortedKeys(oldCodes) {
+			if !newCodes[code] {
+				d.ToppingChanges = append(d.ToppingChanges, productToppingChange(old, oldProduct, code, false))
+			}
+		}
+	}
+}
+
+// diffCoupons finds added and removed coupons and price changes
+func (d *MenuDiff) diffCoupons(old *Menu, new *Menu) {
+	for _, coupon := range new.GetCoupons() {
+		oldCoupon, ok := old.GetCoupon(coupon.Code)
+		if !ok {
+			d.AddedCoupons = append(d.AddedCoupons, MenuItemChange{Code: coupon.Code, Name: coupon.Name, Price: coupon.Price})
+			continue
+		}
+		if oldCoupon.Price != coupon.Price {
+			d.CouponPriceChanges = append(d.CouponPriceChanges, menuPriceChange(coupon.Code, "", coupon.Name, oldCoupon.Price, coupon.Price))
+		}
+	}
+	for _, coupon := range old.GetCoupons() {
+		if _, ok := new.Coupons[coupon.Code]; !ok {
+			d.RemovedCoupons = append(d.RemovedCoupons, MenuItemChange{Code: coupon.Code, Name: coupon.Name, Price: coupon.Price})
+		}
+	}
+}
+
+// menuProductChange describes a product of a menu
+func menuProductChange(menu *Menu, code string) MenuProductChange {
+	product, _ := menu.LookupProduct(code)
+	return MenuProductChange{Code: code, Name: product.Name, ProductType: product.ProductType}
+}
+
+// menuVariantChange describes a variant of a menu
+func menuVariantChange(menu *Menu, code string) MenuItemChange {
+	variant, _ := menu.GetVariant(code)
+	change := MenuItemChange{Code: code}
+	change.Name, _ = variant["Name"].(string)
+	change.ProductCode, _ = variant["ProductCode"].(string)
+	change.Price, _ = menu.GetVariantPrice(code)
+	return change
+}
+
+// menuPriceChange describes a price change
+func menuPriceChange(code string, productCode string, name string, oldPrice Money, newPrice Money) MenuPriceChange {
+	change, _ := newPrice.Sub(oldPrice)
+	return MenuPriceChange{
+		Code:        code,
+		ProductCode: productCode,
+		Name:        name,
+		OldPrice:    oldPrice,
+		NewPrice:    newPrice,
+		Change:      change,
+	}
+}
+
+// menuToppings returns the toppings of a menu keyed by category and code
+func menuToppings(menu *Menu) map[string]MenuToppingChange {
+	set := make(map[string]MenuToppingChange)
+	for category, toppings := range menu.Toppings {
+		toppings, _ := toppings.(map[string]interface{})
+		for code, data := range toppings {
+			data, _ := data.(map[string]interface{})
+			name, _ := data["Name"].(string)
+			set[category+"/"+code] = MenuToppingChange{Code: code, Name: name, Category: category, Available: true}
+		}
+	}
+	return set
+}
+
+// availableToppings returns the codes of the toppings a product can have
+func availableToppings(product *Product) map[string]bool {
+	codes := make(map[string]bool)
+	available, _ := product.GetDominosAPIResponse()["AvailableToppings"].(string)
+	for _, code := range optionCodes(available) {
+		codes[code] = true
+	}
+	return codes
+}
+
+// productToppingChange describes a topping of a product
+func productToppingChange(menu *Menu, product *Product, code string, available bool) MenuToppingChange {
+	change := MenuToppingChange{Code: code, Category: product.ProductType, ProductCode: product.Code, Available: available}
+	if toppings, ok := menu.Toppings[product.ProductType].(map[string]interface{}); ok {
+		if data, ok := toppings[code].(map[string]interface{}); ok {
+			change.Name, _ = data["Name"].(string)
+		}
+	}
+	return change
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDiffMenus(t *testing.T) {
+	old, new := loadMenu(t), loadMenu(t)
+
+	delete(new.Products, "F_PARMT")
+	delete(new.Variants, "B8PCPT")
+	new.Variants["2LSPRITE"] = map[string]interface{}{"Code": "2LSPRITE", "ProductCode": "F_COKE", "Name": "Sprite 2-Liter", "Price": "3.49"}
+	new.Variants["14SCREEN"].(map[string]interface{})["Price"] = "16.49"
+	new.Products["S_PIZZA"].(map[string]interface{})["AvailableToppings"] = "X=0:0.5:1:1.5,C=0:0.5:1:1.5,P=0:0.5:1:1.5,M,Z,G,H"
+	new.Coupons["9174"] = map[string]interface{}{"Code": "9174", "Name": "Carryout Special", "Price": "7.99"}
+
+	diff := DiffMenus(old, new)
+
+	if len(diff.RemovedProducts) != 1 || diff.RemovedProducts[0].Code != "F_PARMT" {
+		t.Errorf("RemovedProducts = %+v", diff.RemovedProducts)
+	}
+	if len(diff.RemovedVariants) != 1 || diff.RemovedVariants[0].Price != NewMoney(799, "USD") {
+		t.Errorf("RemovedVariants = %+v", diff.RemovedVariants)
+	}
+	if len(diff.AddedVariants) != 1 || diff.AddedVariants[0].Code != "2LSPRITE" {
+		t.Errorf("AddedVariants = %+v", diff.AddedVariants)
+	}
+	if len(diff.PriceChanges) != 1 || diff.PriceChanges[0].Code != "14SCREEN" || diff.PriceChanges[0].Change != NewMoney(50, "USD") {
+		t.Errorf("PriceChanges = %+v", diff.PriceChanges)
+	}
+	if len(diff.ToppingChanges) != 1 || diff.ToppingChanges[0] != (MenuToppingChange{Code: "N", Name: "Pineapple", Category: "Pizza", ProductCode: "S_PIZZA"}) {
+		t.Errorf("ToppingChanges = %+v", diff.ToppingChanges)
+	}
+	if len(diff.AddedCoupons) != 1 || diff.AddedCoupons[0].Code != "9174" || len(diff.RemovedCoupons) != 0 {
+		t.Errorf("coupons added %+v, removed %+v", diff.AddedCoupons, diff.RemovedCoupons)
+	}
+
+	text := diff.String()
+	for _, line := range []string{"- F_PARMT Parmesan Bread Twists", "14SCREEN Large (14\") Hand Tossed Pizza: 15.99 USD -> 16.49 USD (+0.50 USD)", "- N Pineapple (Pizza) on S_PIZZA"} {
+		if !strings.Contains(text, line) {
+			t.Errorf("String() = %q, missing %q", text, line)
+		}
+	}
+
+	if _, err := json.Marshal(diff); err != nil {
+		t.Errorf("diff doesn't encode: %v", err)
+	}
+	if !DiffMenus(old, loadMenu(t)).IsEmpty() {
+		t.Error("diff of the same menu isn't empty")
+	}
+}
+
+func TestDiffMenusCurrencies(t *testing.T) {
+	old, new := loadMenu(t), loadMenu(t)
+	old.Currency, new.Currency = "USD", "CAD"
+
+	diff := DiffMenus(old, new)
+	var change *MenuPriceChange
+	for i := range diff.PriceChanges {
+		if diff.PriceChanges[i].Code == "14SCREEN" {
+			change = &diff.PriceChanges[i]
+		}
+	}
+	if change == nil {
+		t.Fatalf("PriceChanges = %+v, want 14SCREEN in two currencies", diff.PriceChanges)
+	}
+	if change.OldPrice != NewMoney(1599, "USD") || change.NewPrice != NewMoney(1599, "CAD") {
+		t.Errorf("14SCREEN = %v -> %v, want each menu's currency", change.OldPrice, change.NewPrice)
+	}
+}
@@ -0,0 +1,295 @@
+package models
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/sqlite"
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// MenuExportSchemaVersion is written to the metadata table and only changes
+// when a column is renamed or removed; new columns are added at the end.
+// Version 2 replaced the REAL price columns with INTEGER price_minor.
+const MenuExportSchemaVersion = 2
+
+// Tables of a menu export, in the order they are written
+var menuExportSchema = []menuExportTable{
+	{"metadata", []menuExportColumn{
+		{"schema_version", "INTEGER"}, {"store_id", "TEXT"}, {"business_date", "TEXT"},
+		{"language", "TEXT"}, {"currency", "TEXT"}, {"exported_at", "TEXT"},
+	}},
+	{"categories", []menuExportColumn{
+		{"store_id", "TEXT"}, {"exported_at", "TEXT"}, {"section", "TEXT"}, {"code", "TEXT"},
+		{"parent_code", "TEXT"}, {"name", "TEXT"}, {"description", "TEXT"}, {"product_codes", "TEXT"},
+	}},
+	{"products", []menuExportColumn{
+		{"store_id", "TEXT"}, {"exported_at", "TEXT"}, {"code", "TEXT"}, {"name", "TEXT"},
+		{"product_type", "TEXT"}, {"description", "TEXT"}, {"image_code", "TEXT"},
+		{"default_toppings", "TEXT"}, {"default_sides", "TEXT"}, {"available_toppings", "TEXT"},
+		{"variant_codes", "TEXT"},
+	}},
+	{"variants", []menuExportColumn{
+		{"store_id", "TEXT"}, {"exported_at", "TEXT"}, {"code", "TEXT"}, {"product_code", "TEXT"},
+		{"name", "TEXT"}, {"size_code", "TEXT"}, {"flavor_code", "TEXT"}, {"price_minor", "INTEGER"},
+		{"currency", "TEXT"},
+	}},
+	{"toppings", []menuExportColumn{
+		{"store_id", "TEXT"}, {"exported_at", "TEXT"}, {"category", "TEXT"}, {"code", "TEXT"},
+		{"name", "TEXT"}, {"description", "TEXT"},
+	}},
+	{"coupons", []menuExportColumn{
+		{"store_id", "TEXT"}, {"exported_at", "TEXT"}, {"code", "TEXT"}, {"name", "TEXT"},
+		{"description", "TEXT"}, {"price_minor", "INTEGER"}, {"currency", "TEXT"}, {"service_methods", "TEXT"},
+	}},
+}
+
+// menuExportTable is the schema of an exported table
+type menuExportTable struct {
+	name    string
+	columns []menuExportColumn
+}
+
+// menuExportColumn is a column and its SQLite type
+type menuExportColumn struct {
+	name    string
+	sqlType string
+}
+
+// MenuTable is a table of a flattened menu. Values are strings, int64s, or nil
+// where the menu has no value. Prices are int64 amounts in the currency's minor
+// unit, such as cents.
+type MenuTable struct {
+	Name    string
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// MenuExport flattens a menu into the tables metadata, categories, products,
+// variants, toppings and coupons, and writes them as CSV, newline-delimited
+// JSON or a SQLite database. Every row carries the store ID and export time so
+// exports of several stores or days can be loaded side by side.
+type MenuExport struct {
+	Menu *Menu
+	// StoreID, BusinessDate and Language are read from the menu's "Misc" section
+	StoreID      string
+	BusinessDate string
+	Language     string
+	ExportedAt   time.Time
+}
+
+// NewMenuExport creates an export of a menu, timestamped now
+func NewMenuExport(menu *Menu) *MenuExport {
+	export := &MenuExport{Menu: menu, ExportedAt: time.Now().UTC()}
+
+	if misc, ok := lookupKey(menu.GetDominosAPIResponse(), "Misc").(map[string]interface{}); ok {
+		export.StoreID = menuText(lookupKey(misc, "StoreID"))
+		export.BusinessDate = menuText(lookupKey(misc, "BusinessDate"))
+		export.Language = menuText(lookupKey(misc, "LanguageCode"))
+	}
+	return export
+}
+
+// Tables returns the menu as tables, with rows sorted by code
+func (e *MenuExport) Tables() []*MenuTable {
+	tables := make([]*MenuTable, 0, len(menuExportSchema))
+	for _, schema := range menuExportSchema {
+		table := &MenuTable{Name: schema.name, Rows: make([][]interface{}, 0)}
+		for _, column := range schema.columns {
+			table.Columns = append(table.Columns, column.name)
+		}
+		tables = append(tables, table)
+	}
+
+	currency := e.Menu.currency()
+	exportedAt := e.ExportedAt.UTC().Format(time.RFC3339)
+	row := func(table *MenuTable, values ...interface{}) {
+		if table.Name != "metadata" {
+			values = append([]interface{}{e.StoreID, exportedAt}, values...)
+		}
+		table.Rows = append(table.Rows, values)
+	}
+
+	row(tables[0], int64(MenuExportSchemaVersion), e.StoreID, e.BusinessDate, e.Language, currency, exportedAt)
+
+	for _, section := range sortedKeys(e.Menu.Categorization) {
+		data, _ := e.Menu.Categorization[section].(map[string]interface{})
+		e.exportCategories(tables[1], row, section, "", lookupKey(data, "Categories"))
+	}
+
+	for _, product := range e.Menu.AllProducts() {
+		available, _ := product.GetDominosAPIResponse()["AvailableToppings"].(string)
+		row(tables[2], product.Code, product.Name, product.ProductType, product.Description, product.ImageCode,
+			product.DefaultToppings, product.DefaultSides, available, strings.Join(product.Variants, ","))
+	}
+
+	for _, code := range sortedKeys(e.Menu.Variants) {
+		variant, _ := e.Menu.GetVariant(code)
+		var price interface{}
+		if amount, ok := e.Menu.GetVariantPrice(code); ok {
+			price = amount.Amount
+		}
+		row(tables[3], code, menuText(variant["ProductCode"]), menuText(variant["Name"]),
+			menuText(variant["SizeCode"]), menuText(variant["FlavorCode"]), price, currency)
+	}
+
+	for _, category := range sortedKeys(e.Menu.Toppings) {
+		toppings, _ := e.Menu.Toppings[category].(map[string]interface{})
+		for _, code := range sortedKeys(toppings) {
+			topping, _ := toppings[code].(map[string]interface{})
+			row(tables[4], category, code, menuText(topping["Name"]), menuText(topping["Description"]))
+		}
+	}
+
+	for _, coupon := range e.Menu.GetCoupons() {
+		var price interface{}
+		if !coupon.Price.IsZero() {
+			price = coupon.Price.Amount
+		}
+		row(tables[5], coupon.Code, coupon.Name, coupon.Description, price, currency, strings.Join(coupon.ServiceMethods, ","))
+	}
+
+	return tables
+}
+
+// exportCategories adds a list or map of categories and their subcategories
+func (e *MenuExport) exportCategories(table *MenuTable, row func(*MenuTable, ...interface{}), section string, parent string, categories interface{}) {
+	codes := make([]string, 0)
+	list := make([]map[string]interface{}, 0)
+	switch categories := categories.(type) {
+	case []interface{}:
+		for _, category := range categories {
+			if category, ok := category.(map[string]interface{}); ok {
+				codes = append(codes, menuText(lookupKey(category, "Code")))
+				list = append(list, category)
+			}
+		}
+	case map[string]interface{}:
+		// Keyed by code, which the category may not repeat
+		for _, code := range sortedKeys(categories) {
+			if category, ok := categories[code].(map[string]interface{}); ok {
+				codes = append(codes, code)
+				list = append(list, category)
+			}
+		}
+	}
+
+	for i, category := range list {
+		row(table, section, codes[i], parent, menuText(lookupKey(category, "Name")),
+			menuText(lookupKey(category, "Description")), strings.Join(stringList(lookupKey(category, "Products")), ","))
+		e.exportCategories(table, row, section, codes[i], lookupKey(category, "Categories"))
+	}
+}
+
+// WriteCSV writes each table to dir as <table>.csv with a header row
+func (e *MenuExport) WriteCSV(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for _, table := range e.Tables() {
+		file, err := os.Create(filepath.Join(dir, table.Name+".csv"))
+		if err != nil {
+			return err
+		}
+		if err := table.WriteCSV(file); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCSV writes the table as CSV with a header row
+func (t *MenuTable) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(t.Columns); err != nil {
+		return err
+	}
+
+	record := make([]string, len(t.Columns))
+	for _, row := range t.Rows {
+		for i, value := range row {
+			record[i] = exportText(value)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteNDJSON writes every row of every table as a JSON object on its own line,
+// with a "table" key naming its table and the columns in schema order
+func (e *MenuExport) WriteNDJSON(w io.Writer) error {
+	buffered := bufio.NewWriter(w)
+	for _, table := range e.Tables() {
+		for _, row := range table.Rows {
+			line := []byte(`{"table":`)
+			line = strconv.AppendQuote(line, table.Name)
+			for i, value := range row {
+				encoded, err := json.Marshal(value)
+				if err != nil {
+					return err
+				}
+				line = append(line, ',')
+				line = strconv.AppendQuote(line, table.Columns[i])
+				line = append(line, ':')
+				line = append(line, encoded...)
+			}
+			line = append(line, "}\n"...)
+
+			if _, err := buffered.Write(line); err != nil {
+				return err
+			}
+		}
+	}
+	return buffered.Flush()
+}
+
+// WriteSQLite writes the tables to a new SQLite database file at path,
+// replacing any file there. The file is written directly, so no SQLite library
+// or cgo is needed; the tables have no indexes.
+func (e *MenuExport) WriteSQLite(path string) error {
+	db := sqlite.NewWriter()
+	for i, table := range e.Tables() {
+		columns := make([]string, 0, len(table.Columns))
+		for _, column := range menuExportSchema[i].columns {
+			columns = append(columns, column.name+" "+column.sqlType)
+		}
+		db.AddTable(table.Name, "CREATE TABLE "+table.Name+" ("+strings.Join(columns, ", ")+")", table.Rows)
+	}
+
+	data, ok := db.Bytes()
+	if !ok {
+		return utils.NewDominosProductsError("Menu export schema does not fit on the first page of a SQLite file")
+	}
+	return writeFileAtomic(path, data)
+}
+
+// exportText formats a value for CSV
+func exportText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	return ""
+}
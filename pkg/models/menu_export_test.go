@@ -0,0 +1,133 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testExport exports the test menu at a fixed time
+func testExport(t *testing.T) *MenuExport {
+	t.Helper()
+
+	menu := loadMenu(t)
+	menu.Currency = "USD"
+	export := NewMenuExport(menu)
+	export.ExportedAt = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	return export
+}
+
+// exportTable returns a table of an export by name
+func exportTable(t *testing.T, tables []*MenuTable, name string) *MenuTable {
+	t.Helper()
+
+	for _, table := range tables {
+		if table.Name == name {
+			return table
+		}
+	}
+	t.Fatalf("no %s table", name)
+	return nil
+}
+
+func TestMenuExportTables(t *testing.T) {
+	export := testExport(t)
+	if export.StoreID != "4336" || export.BusinessDate != "2026-10-19" || export.Language != "en" {
+		t.Errorf("export = %+v, want the menu's Misc section", export)
+	}
+
+	tables := export.Tables()
+	metadata := exportTable(t, tables, "metadata")
+	want := []interface{}{int64(MenuExportSchemaVersion), "4336", "2026-10-19", "en", "USD", "2026-10-19T12:00:00Z"}
+	if len(metadata.Rows) != 1 || !reflect.DeepEqual(metadata.Rows[0], want) {
+		t.Errorf("metadata = %v, want %v", metadata.Rows, want)
+	}
+
+	variants := exportTable(t, tables, "variants")
+	if len(variants.Rows) != len(export.Menu.Variants) {
+		t.Fatalf("got %d variants, want %d", len(variants.Rows), len(export.Menu.Variants))
+	}
+	for _, row := range variants.Rows {
+		if row[2] == "14SCREEN" {
+			want := []interface{}{"4336", "2026-10-19T12:00:00Z", "14SCREEN", "S_PIZZA", "Large (14\") Hand Tossed Pizza", "14", "HANDTOSS", int64(1599), "USD"}
+			if !reflect.DeepEqual(row, want) {
+				t.Errorf("14SCREEN = %v, want %v", row, want)
+			}
+		}
+	}
+
+	coupons := exportTable(t, tables, "coupons")
+	if len(coupons.Rows) != 1 || coupons.Rows[0][5] != int64(1398) {
+		t.Errorf("coupons = %v, want 9193 at 1398 cents", coupons.Rows)
+	}
+}
+
+func TestMenuExportNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testExport(t).WriteNDJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var row map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			t.Fatalf("line %q: %v", line, err)
+		}
+		if row["table"] == "variants" && row["code"] == "2LCOKE" {
+			found = true
+			if row["price_minor"] != 349.0 || row["currency"] != "USD" {
+				t.Errorf("2LCOKE = %v", row)
+			}
+		}
+	}
+	if !found {
+		t.Error("2LCOKE not exported")
+	}
+}
+
+func TestMenuExportSQLite(t *testing.T) {
+	sqlite3, err := exec.LookPath("sqlite3")
+	if err != nil {
+		t.Skip("sqlite3 isn't installed")
+	}
+
+	export := testExport(t)
+	// Enough rows for interior pages and a description long enough to overflow
+	for i := 0; i < 500; i++ {
+		code := fmt.Sprintf("V%04d", i)
+		export.Menu.Variants[code] = map[string]interface{}{"Code": code, "ProductCode": "S_PIZZA", "Name": "Variant " + code, "Price": fmt.Sprintf("1.%02d", i%100)}
+	}
+	long := strings.Repeat("long ", 2000)
+	export.Menu.Toppings["Pizza"].(map[string]interface{})["L"] = map[string]interface{}{"Name": "Long", "Description": long}
+
+	path := filepath.Join(t.TempDir(), "menu.db")
+	if err := export.WriteSQLite(path); err != nil {
+		t.Fatal(err)
+	}
+
+	sql := "PRAGMA integrity_check;"
+	want := []string{"ok"}
+	for _, table := range export.Tables() {
+		sql += " SELECT count(*) FROM " + table.Name + ";"
+		want = append(want, fmt.Sprint(len(table.Rows)))
+	}
+	sql += " SELECT typeof(price_minor), price_minor FROM variants WHERE code = '14SCREEN';"
+	want = append(want, "integer|1599")
+	sql += " SELECT length(description) FROM toppings WHERE code = 'L';"
+	want = append(want, fmt.Sprint(len(long)))
+
+	out, err := exec.Command(sqlite3, path, sql).CombinedOutput()
+	if err != nil {
+		t.Fatalf("sqlite3: %v: %s", err, out)
+	}
+	if got := strings.Split(strings.TrimSpace(string(out)), "\n"); !reflect.DeepEqual(got, want) {
+		t.Errorf("sqlite3 says %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,601 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Defaults used to pick a variant when a query doesn't name a size or crust
+const (
+	DefaultSearchSize  = "14"
+	DefaultSearchCrust = "HANDTOSS"
+)
+
+// DefaultSearchSynonyms maps words and phrases people use to the words on the menu
+var DefaultSearchSynonyms = map[string]string{
+	"sm":          "small",
+	"med":         "medium",
+	"lg":          "large",
+	"lrg":         "large",
+	"xl":          "xlarge",
+	"x large":     "xlarge",
+	"extra large": "xlarge",
+	"handtossed":  "hand tossed",
+	"deep dish":   "pan",
+	"pie":         "pizza",
+	"za":          "pizza",
+	"pep":         "pepperoni",
+	"pepp":        "pepperoni",
+	"roni":        "pepperoni",
+	"shroom":      "mushroom",
+	"soda":        "coke",
+	"pop":         "coke",
+	"gf":          "gluten free",
+}
+
+// Words that don't help find a product
+var searchStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "with": true, "of": true, "on": true,
+	"some": true, "please": true, "crust": true, "style": true, "size": true, "inch": true,
+}
+
+// MenuQuery is what a search query asks for
+type MenuQuery struct {
+	// Terms are the words left once size and crust words are taken out
+	Terms []string
+	// Size and Crust are the words naming them, such as "large" and "thin"
+	Size  string
+	Crust string
+	// Toppings are the topping codes named, by category such as "Pizza"
+	Toppings map[string][]string
+}
+
+// MenuSearchResult is a product matching a query, resolved to the variant to order
+type MenuSearchResult struct {
+	ProductCode string
+	ProductName string
+	VariantCode string
+	VariantName string
+	SizeCode    string
+	FlavorCode  string
+	// Toppings named in the query that the product takes
+	Toppings []string
+	Score    float64
+}
+
+// MenuSearch is a search index over a menu's products
+type MenuSearch struct {
+	// DefaultSize and DefaultCrust pick the variant when a query names neither
+	DefaultSize  string
+	DefaultCrust string
+
+	menu     *Menu
+	synonyms map[string]string
+	products []*searchProduct
+	toppings []searchTopping
+	sizes    map[string]map[string]string // category -> word -> size code
+	crusts   map[string]map[string]string // category -> word -> flavor code
+}
+
+// searchProduct is a product's searchable text
+type searchProduct struct {
+	code     string
+	name     string
+	category string
+	fields   []searchField
+	variants []string
+	toppings map[string]bool // codes available or default on the product
+	recipe   map[string]bool // default topping codes
+
+	defaultToppings string
+}
+
+// searchField is a tokenized field with the weight of a match in it
+type searchField struct {
+	tokens []string
+	weight float64
+}
+
+// searchTopping is a topping's name tokens
+type searchTopping struct {
+	category string
+	code     string
+	tokens   []string
+}
+
+// NewMenuSearch indexes a menu's product and variant names, descriptions,
+// alternative names, short descriptions and topping names
+func NewMenuSearch(menu *Menu) *MenuSearch {
+	s := &MenuSearch{
+		DefaultSize:  DefaultSearchSize,
+		DefaultCrust: DefaultSearchCrust,
+		menu:         menu,
+		synonyms:     make(map[string]string, len(DefaultSearchSynonyms)),
+	}
+	for from, to := range DefaultSearchSynonyms {
+		s.synonyms[from] = to
+	}
+	s.build()
+	return s
+}
+
+// AddSynonym makes a word or phrase match another, such as "meat lovers" for
+// "meatzza", and reindexes the menu
+func (s *MenuSearch) AddSynonym(from string, to string) {
+	s.synonyms[strings.Join(cleanWords(from), " ")] = strings.Join(cleanWords(to), " ")
+	s.build()
+}
+
+// build indexes the menu
+func (s *MenuSearch) build() {
+	s.sizes = s.categoryWords(s.menu.Sizes, nil)
+	s.crusts = s.categoryWords(s.menu.Flavors, searchStopWords)
+
+	s.toppings = nil
+	for category, toppings := range s.menu.Toppings {
+		toppings, _ := toppings.(map[string]interface{})
+		for code, data := range toppings {
+			data, _ := data.(map[string]interface{})
+			name, _ := data["Name"].(string)
+			if tokens := s.tokenize(name); len(tokens) > 0 {
+				s.toppings = append(s.toppings, searchTopping{category: category, code: code, tokens: tokens})
+			}
+		}
+	}
+	// Longer names first, so "Banana Peppers" is found before "Peppers"
+	sort.Slice(s.toppings, func(i, j int) bool {
+		if len(s.toppings[i].tokens) != len(s.toppings[j].tokens) {
+			return len(s.toppings[i].tokens) > len(s.toppings[j].tokens)
+		}
+		return s.toppings[i].code < s.toppings[j].code
+	})
+
+	s.products = nil
+	for _, product := range s.menu.AllProducts() {
+		indexed := &searchProduct{
+			code:     product.Code,
+			name:     product.Name,
+			category: product.ProductType,
+			variants: product.Variants,
+			toppings: make(map[string]bool),
+			recipe:   make(map[string]bool),
+
+			defaultToppings: product.DefaultToppings,
+		}
+
+		indexed.fields = []searchField{
+			{tokens: s.tokenize(product.Name), weight: 1},
+			{tokens: s.tokenize(menuText(s.menu.AlternativeProductNames[product.Code])), weight: 0.9},
+			{tokens: s.tokenize(menuText(s.menu.ShortProductDescriptions[product.Code])), weight: 0.6},
+			{tokens: s.tokenize(product.Description), weight: 0.4},
+		}
+		for _, code := range product.Variants {
+			if variant, ok := s.menu.GetVariant(code); ok {
+				name, _ := variant["Name"].(string)
+				indexed.fields = append(indexed.fields, searchField{tokens: s.tokenize(name), weight: 0.8})
+			}
+		}
+
+		data := product.GetDominosAPIResponse()
+		available, _ := data["AvailableToppings"].(string)
+		for _, code := range optionCodes(available) {
+			indexed.toppings[code] = true
+		}
+		for _, code := range optionCodes(product.DefaultToppings) {
+			indexed.toppings[code] = true
+			indexed.recipe[code] = true
+		}
+
+		s.products = append(s.products, indexed)
+	}
+}
+
+// categoryWords maps the distinctive words of each size or flavor name in a
+// category to its code. Words shared by several names in a category are left out.
+func (s *MenuSearch) categoryWords(entries map[string]interface{}, skip map[string]bool) map[string]map[string]string {
+	words := make(map[string]map[string]string)
+	for category, codes := range entries {
+		codes, _ := codes.(map[string]interface{})
+		counts := make(map[string]int)
+		byWord := make(map[string]string)
+
+		for code, data := range codes {
+			data, _ := data.(map[string]interface{})
+			name, _ := data["Name"].(string)
+
+			seen := map[string]bool{strings.ToLower(code): true}
+			for _, token := range s.tokenize(name) {
+				seen[token] = true
+			}
+			for token := range seen {
+				if skip[token] {
+					continue
+				}
+				counts[token]++
+				byWord[token] = code
+			}
+		}
+
+		words[category] = make(map[string]string)
+		for token, code := range byWord {
+			if counts[token] == 1 {
+				words[category][token] = code
+			}
+		}
+	}
+	return words
+}
+
+// ParseQuery takes the size and crust words out of a query and finds the
+// toppings it names
+func (s *MenuSearch) ParseQuery(query string) MenuQuery {
+	parsed := MenuQuery{Toppings: make(map[string][]string)}
+
+	for _, token := range s.tokenize(query) {
+		switch {
+		case searchStopWords[token]:
+		case parsed.Size == "" && s.isCategoryWord(s.sizes, token):
+			parsed.Size = token
+		case parsed.Crust == "" && s.isCategoryWord(s.crusts, token):
+			parsed.Crust = token
+		case parsed.Crust != "" && s.crustCode(token) != "" && s.crustCode(token) == s.crustCode(parsed.Crust):
+			// The rest of a crust name, like "tossed" after "hand"
+		default:
+			parsed.Terms = append(parsed.Terms, token)
+		}
+	}
+
+	for _, topping := range s.toppings {
+		if containsTokens(parsed.Terms, topping.tokens) {
+			parsed.Toppings[topping.category] = appendUnique(parsed.Toppings[topping.category], topping.code)
+		}
+	}
+
+	return parsed
+}
+
+// Search returns up to limit products matching the query, best first, each
+// resolved to the variant for the query's size and crust. A limit of zero
+// returns every match.
+func (s *MenuSearch) Search(query string, limit int) []MenuSearchResult {
+	parsed := s.ParseQuery(query)
+	if len(parsed.Terms) == 0 && parsed.Size == "" && parsed.Crust == "" {
+		return nil
+	}
+
+	results := make([]MenuSearchResult, 0)
+	for _, product := range s.products {
+		if result, ok := s.score(product, parsed); ok {
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if len(results[i].ProductName) != len(results[j].ProductName) {
+			return len(results[i].ProductName) < len(results[j].ProductName)
+		}
+		return results[i].ProductCode < results[j].ProductCode
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// Minimum score for a product to be returned
+const minSearchScore = 0.35
+
+// score rates how well a product matches a query
+func (s *MenuSearch) score(product *searchProduct, query MenuQuery) (MenuSearchResult, bool) {
+	result := MenuSearchResult{ProductCode: product.code, ProductName: product.name}
+
+	toppings := query.Toppings[s.categoryKey(s.menu.Toppings, product.category)]
+	for _, code := range toppings {
+		if product.toppings[code] {
+			result.Toppings = append(result.Toppings, code)
+		}
+	}
+
+	// Each term scores its best match in the product's fields or toppings
+	score := 0.0
+	for _, term := range query.Terms {
+		best := 0.0
+		for _, field := range product.fields {
+			for _, token := range field.tokens {
+				if match := tokenMatch(term, token) * field.weight; match > best {
+					best = match
+				}
+			}
+		}
+		// Naming a topping a product already has fits a specialty pizza a little
+		// less than building your own with it
+		if code := s.namedTopping(term, result.Toppings); code != "" {
+			match := 0.6
+			if product.recipe[code] {
+				match = 0.5
+			}
+			if match > best {
+				best = match
+			}
+		}
+		score += best
+	}
+	if len(query.Terms) > 0 {
+		score /= float64(len(query.Terms))
+	}
+
+	// Then the size and crust must be available
+	sizeCode, sizeOK := s.wordCode(s.sizes, product.category, query.Size)
+	crustCode, crustOK := s.wordCode(s.crusts, product.category, query.Crust)
+	variant, fits := s.resolveVariant(product, sizeCode, crustCode)
+	for _, asked := range []struct {
+		word string
+		ok   bool
+	}{{query.Size, sizeOK}, {query.Crust, crustOK}} {
+		switch {
+		case asked.word == "":
+		case asked.ok && fits:
+			score += 0.15
+		default:
+			score *= 0.6
+		}
+	}
+
+	if score < minSearchScore || variant == "" {
+		return result, false
+	}
+
+	result.Score = score
+	result.VariantCode = variant
+	if data, ok := s.menu.GetVariant(variant); ok {
+		result.VariantName, _ = data["Name"].(string)
+		result.SizeCode, _ = data["SizeCode"].(string)
+		result.FlavorCode, _ = data["FlavorCode"].(string)
+	}
+	return result, true
+}
+
+// resolveVariant picks the product's variant for a size and flavor code, using
+// the defaults for whichever isn't given. It reports whether the variant has
+// the size and flavor that were asked for.
+func (s *MenuSearch) resolveVariant(product *searchProduct, sizeCode string, flavorCode string) (string, bool) {
+	if len(product.variants) == 0 {
+		return "", false
+	}
+
+	best, bestScore := product.variants[0], -1
+	for _, code := range product.variants {
+		data, _ := s.menu.GetVariant(code)
+		size, _ := data["SizeCode"].(string)
+		flavor, _ := data["FlavorCode"].(string)
+
+		score := 0
+		switch {
+		case sizeCode != "" && strings.EqualFold(size, sizeCode):
+			score += 4
+		case sizeCode == "" && strings.EqualFold(size, s.DefaultSize):
+			score++
+		}
+		switch {
+		case flavorCode != "" && strings.EqualFold(flavor, flavorCode):
+			score += 4
+		case flavorCode == "" && strings.EqualFold(flavor, s.DefaultCrust):
+			score++
+		}
+
+		if score > bestScore {
+			best, bestScore = code, score
+		}
+	}
+
+	wanted := 0
+	if sizeCode != "" {
+		wanted += 4
+	}
+	if flavorCode != "" {
+		wanted += 4
+	}
+	return best, bestScore >= wanted
+}
+
+// wordCode returns the size or flavor code a word names in a product's category
+func (s *MenuSearch) wordCode(words map[string]map[string]string, category string, word string) (string, bool) {
+	if word == "" {
+		return "", false
+	}
+	for key, codes := range words {
+		if strings.EqualFold(key, category) {
+			code, ok := codes[word]
+			return code, ok
+		}
+	}
+	return "", false
+}
+
+// isCategoryWord reports whether a word names a size or crust in any category
+func (s *MenuSearch) isCategoryWord(words map[string]map[string]string, word string) bool {
+	for _, codes := range words {
+		if _, ok := codes[word]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// crustCode returns the flavor code a word names in any category
+func (s *MenuSearch) crustCode(word string) string {
+	for _, codes := range s.crusts {
+		if code, ok := codes[word]; ok {
+			return code
+		}
+	}
+	return ""
+}
+
+// categoryKey finds a category in a menu section regardless of case
+func (s *MenuSearch) categoryKey(section map[string]interface{}, category string) string {
+	for key := range section {
+		if strings.EqualFold(key, category) {
+			return key
+		}
+	}
+	return category
+}
+
+// namedTopping returns which of the toppings a term is part of the name of
+func (s *MenuSearch) namedTopping(term string, codes []string) string {
+	for _, topping := range s.toppings {
+		for _, code := range codes {
+			if topping.code == code && containsTokens(topping.tokens, []string{term}) {
+				return code
+			}
+		}
+	}
+	return ""
+}
+
+// tokenize normalizes text into lowercase, singular words with synonyms applied
+func (s *MenuSearch) tokenize(text string) []string {
+	words := " " + strings.Join(cleanWords(text), " ") + " "
+
+	// Phrases first, longest first, so "extra large" wins over "large"
+	phrases := make([]string, 0)
+	for from := range s.synonyms {
+		if strings.Contains(from, " ") {
+			phrases = append(phrases, from)
+		}
+	}
+	sort.Slice(phrases, func(i, j int) bool { return len(phrases[i]) > len(phrases[j]) })
+	for _, phrase := range phrases {
+		words = strings.ReplaceAll(words, " "+phrase+" ", " "+s.synonyms[phrase]+" ")
+	}
+
+	tokens := make([]string, 0)
+	for _, word := range strings.Fields(words) {
+		if synonym, ok := s.synonyms[word]; ok {
+			word = synonym
+		}
+		for _, token := range strings.Fields(word) {
+			tokens = append(tokens, stemWord(token))
+		}
+	}
+	return tokens
+}
+
+// cleanWords lowercases text and splits it into words of letters and digits
+func cleanWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// stemWord makes a plural word singular
+func stemWord(word string) string {
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// tokenMatch rates how closely a query term matches a word, from 0 to 1
+func tokenMatch(term string, token string) float64 {
+	switch {
+	case term == token:
+		return 1
+	case len(term) >= 3 && strings.HasPrefix(token, term):
+		return 0.85
+	}
+
+	distance := levenshtein(term, token)
+	switch {
+	case len(term) >= 4 && distance <= 1:
+		return 0.8
+	case len(term) >= 7 && distance <= 2:
+		return 0.7
+	}
+	return 0
+}
+
+// containsTokens reports whether every one of want matches a token in tokens
+func containsTokens(tokens []string, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, token := range tokens {
+			if token == w || (len(w) >= 5 && levenshtein(token, w) <= 1) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return len(want) > 0
+}
+
+// levenshtein returns the edit distance between two words
+func levenshtein(a string, b string) int {
+	ar, br := []rune(a), []rune(b)
+	previous := make([]int, len(br)+1)
+	current := make([]int, len(br)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		current[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(br)]
+}
+
+// optionCodes returns the codes of an option list like "X=1,C=1" or
+// "X=0:0.5:1,C=0:1,P"
+func optionCodes(options string) []string {
+	codes := make([]string, 0)
+	for _, option := range strings.Split(options, ",") {
+		code, _, _ := strings.Cut(option, "=")
+		if code = strings.TrimSpace(code); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// menuText joins the text of an AlternativeProductNames or ShortProductDescriptions
+// entry, which may be a string, a list or an object of strings
+func menuText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, menuText(item))
+		}
+		return strings.Join(parts, " ")
+	case map[string]interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, menuText(item))
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, " ")
+	}
+	return ""
+}
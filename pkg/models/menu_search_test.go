@@ -0,0 +1,84 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMenuSearch(t *testing.T) {
+	search := NewMenuSearch(loadMenu(t))
+
+	tests := []struct {
+		query    string
+		product  string
+		variant  string
+		toppings []string
+	}{
+		{"pizza", "S_PIZZA", "14SCREEN", nil},
+		{"large pepperoni pizza", "S_PIZZA", "14SCREEN", []string{"P"}},
+		{"small pizza", "S_PIZZA", "10SCREEN", nil},
+		{"xl pizza", "S_PIZZA", "16SCREEN", nil},
+		{"extra large pizza", "S_PIZZA", "16SCREEN", nil},
+		{"thin crust pizza", "S_PIZZA", "14THIN", nil},
+		{"large brooklyn style pizza", "S_PIZZA", "14BK", nil},
+		{"medium hawaiian", "S_PIZUH", "12SCUH", nil},
+		{"peperoni pie", "S_PIZZA", "14SCREEN", []string{"P"}},
+		{"pizza with banana peppers", "S_PIZZA", "14SCREEN", []string{"Z"}},
+		{"parm twists", "F_PARMT", "B8PCPT", nil},
+		{"soda", "F_COKE", "2LCOKE", nil},
+		{"mac and cheese", "S_MACNCH", "PINPASMC", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results := search.Search(tt.query, 1)
+			if len(results) == 0 {
+				t.Fatal("no results")
+			}
+			got := results[0]
+			if got.ProductCode != tt.product || got.VariantCode != tt.variant {
+				t.Errorf("best match = %s %s, want %s %s", got.ProductCode, got.VariantCode, tt.product, tt.variant)
+			}
+			if !reflect.DeepEqual(got.Toppings, tt.toppings) {
+				t.Errorf("toppings = %v, want %v", got.Toppings, tt.toppings)
+			}
+		})
+	}
+}
+
+func TestMenuSearchNoMatch(t *testing.T) {
+	search := NewMenuSearch(loadMenu(t))
+
+	for _, query := range []string{"", "the", "sushi"} {
+		if results := search.Search(query, 0); len(results) != 0 {
+			t.Errorf("Search(%q) = %v, want nothing", query, results)
+		}
+	}
+}
+
+func TestMenuSearchParseQuery(t *testing.T) {
+	search := NewMenuSearch(loadMenu(t))
+
+	got := search.ParseQuery("Large Hand Tossed Pizza with Pepperoni and Mushrooms")
+	want := MenuQuery{
+		Terms: []string{"pizza", "pepperoni", "mushroom"},
+		Size:  "large",
+		Crust: "hand",
+	}
+	if !reflect.DeepEqual(got.Terms, want.Terms) || got.Size != want.Size || got.Crust != want.Crust {
+		t.Errorf("ParseQuery = %+v, want %+v", got, want)
+	}
+	if toppings := got.Toppings["Pizza"]; len(toppings) != 2 || !containsFold(toppings, "P") || !containsFold(toppings, "M") {
+		t.Errorf("Toppings = %v, want P and M", got.Toppings)
+	}
+}
+
+func TestMenuSearchSynonym(t *testing.T) {
+	search := NewMenuSearch(loadMenu(t))
+	search.AddSynonym("Hawaiian Pie", "Honolulu Hawaiian")
+
+	results := search.Search("hawaiian pie", 1)
+	if len(results) == 0 || results[0].ProductCode != "S_PIZUH" {
+		t.Errorf("Search with synonym = %v, want S_PIZUH", results)
+	}
+}
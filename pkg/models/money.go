@@ -0,0 +1,257 @@
+package models
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// Money is an amount in integer minor units (e.g. cents) of an ISO 4217 currency.
+// It encodes to JSON as a plain decimal number, the way Domino's sends amounts.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// Currencies whose minor unit isn't a hundredth
+var currencyExponents = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"BHD": 3,
+	"KWD": 3,
+	"OMR": 3,
+}
+
+// currencyExponent returns the number of decimal places used by a currency
+func currencyExponent(currency string) int {
+	if exponent, ok := currencyExponents[strings.ToUpper(currency)]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// NewMoney creates an amount from minor units
+func NewMoney(minorUnits int64, currency string) Money {
+	return Money{Amount: minorUnits, Currency: currency}
+}
+
+// MoneyFromFloat converts a decimal amount, rounding half away from zero
+func MoneyFromFloat(amount float64, currency string) Money {
+	scale := math.Pow10(currencyExponent(currency))
+	return Money{Amount: int64(math.Round(amount * scale)), Currency: currency}
+}
+
+// ParseMoney parses a decimal amount such as "12.34" exactly, rounding half away
+// from zero past the currency's minor unit
+func ParseMoney(amount string, currency string) (Money, error) {
+	amount = strings.TrimSpace(amount)
+	original := amount
+	invalid := utils.NewDominosCurrencyError("Invalid amount " + original)
+
+	// Exponent notation is rare enough to go through float64
+	if strings.ContainsAny(amount, "eE") {
+		value, err := strconv.ParseFloat(amount, 64)
+		if err != nil {
+			return Money{}, invalid
+		}
+		return MoneyFromFloat(value, currency), nil
+	}
+
+	negative := false
+	if amount != "" && (amount[0] == '-' || amount[0] == '+') {
+		negative = amount[0] == '-'
+		amount = amount[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(amount, ".")
+	if whole == "" && fraction == "" {
+		return Money{}, invalid
+	}
+	for _, digits := range []string{whole, fraction} {
+		for _, char := range digits {
+			if char < '0' || char > '9' {
+				return Money{}, invalid
+			}
+		}
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	exponent := currencyExponent(currency)
+	roundUp := false
+	if len(fraction) > exponent {
+		roundUp = fraction[exponent] >= '5'
+		fraction = fraction[:exponent]
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	minorUnits, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, invalid
+	}
+	if roundUp {
+		minorUnits++
+	}
+	if negative {
+		minorUnits = -minorUnits
+	}
+
+	return Money{Amount: minorUnits, Currency: currency}, nil
+}
+
+// Float64 returns the amount in major units
+func (m Money) Float64() float64 {
+	return float64(m.Amount) / math.Pow10(currencyExponent(m.Currency))
+}
+
+// Decimal formats the amount in major units, e.g. "12.34"
+func (m Money) Decimal() string {
+	exponent := currencyExponent(m.Currency)
+
+	minorUnits := m.Amount
+	sign := ""
+	if minorUnits < 0 {
+		sign = "-"
+		minorUnits = -minorUnits
+	}
+
+	digits := strconv.FormatInt(minorUnits, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// String formats the amount with its currency, e.g. "12.34 USD"
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.Decimal()
+	}
+	return m.Decimal() + " " + m.Currency
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// WithCurrency returns the same decimal amount labelled with currency. The minor
+// units are rescaled, rounding half away from zero, if the currencies have a
+// different number of decimal places.
+func (m Money) WithCurrency(currency string) Money {
+	shift := currencyExponent(currency) - currencyExponent(m.Currency)
+	for ; shift > 0; shift-- {
+		m.Amount *= 10
+	}
+	if shift < 0 {
+		scale := int64(math.Pow10(-shift))
+		rounded := (abs64(m.Amount) + scale/2) / scale
+		if m.Amount < 0 {
+			rounded = -rounded
+		}
+		m.Amount = rounded
+	}
+
+	m.Currency = currency
+	return m
+}
+
+// Add returns m + other. An amount without a currency adopts the other's.
+func (m Money) Add(other Money) (Money, error) {
+	currency, err := commonCurrency(m, other)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: currency}, nil
+}
+
+// Sub returns m - other. An amount without a currency adopts the other's.
+func (m Money) Sub(other Money) (Money, error) {
+	currency, err := commonCurrency(m, other)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount - other.Amount, Currency: currency}, nil
+}
+
+// Mul returns the amount multiplied by n
+func (m Money) Mul(n int64) Money {
+	return Money{Amount: m.Amount * n, Currency: m.Currency}
+}
+
+// Cmp compares two amounts, returning -1, 0 or 1
+func (m Money) Cmp(other Money) (int, error) {
+	if _, err := commonCurrency(m, other); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case m.Amount < other.Amount:
+		return -1, nil
+	case m.Amount > other.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// abs64 returns the absolute value of n
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// commonCurrency returns the currency two amounts share
+func commonCurrency(a Money, b Money) (string, error) {
+	switch {
+	case a.Currency == "":
+		return b.Currency, nil
+	case b.Currency == "" || strings.EqualFold(a.Currency, b.Currency):
+		return a.Currency, nil
+	}
+	return "", utils.NewDominosCurrencyError("Cannot combine " + a.Currency + " and " + b.Currency + " amounts")
+}
+
+// MarshalJSON encodes the amount as a decimal number
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.Decimal()), nil
+}
+
+// UnmarshalJSON decodes a number or numeric string such as "9.99" in the
+// currency already set on m. An empty string decodes as zero.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	text := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		unquoted, err := strconv.Unquote(text)
+		if err != nil {
+			return err
+		}
+		text = unquoted
+	}
+	if strings.TrimSpace(text) == "" {
+		m.Amount = 0
+		return nil
+	}
+
+	parsed, err := ParseMoney(text, m.Currency)
+	if err != nil {
+		return err
+	}
+
+	m.Amount = parsed.Amount
+	return nil
+}
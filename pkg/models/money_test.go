@@ -0,0 +1,182 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     int64
+	}{
+		{"12.34", "USD", 1234},
+		{"12", "USD", 1200},
+		{"12.3", "USD", 1230},
+		{" 0.99 ", "USD", 99},
+		{".5", "USD", 50},
+		{"5.", "USD", 500},
+		{"-1.25", "CAD", -125},
+		{"+1.25", "CAD", 125},
+		{"1.235", "USD", 124},
+		{"1.234", "USD", 123},
+		{"-1.235", "USD", -124},
+		{"1200", "JPY", 1200},
+		{"1200.5", "JPY", 1201},
+		{"1.234", "BHD", 1234},
+		{"1.5", "BHD", 1500},
+		{"1.5e2", "USD", 15000},
+	}
+	for _, test := range tests {
+		got, err := ParseMoney(test.amount, test.currency)
+		if err != nil {
+			t.Errorf("ParseMoney(%q, %s) failed: %v", test.amount, test.currency, err)
+			continue
+		}
+		if got.Amount != test.want || got.Currency != test.currency {
+			t.Errorf("ParseMoney(%q, %s) = %d %s, want %d %s", test.amount, test.currency, got.Amount, got.Currency, test.want, test.currency)
+		}
+	}
+}
+
+func TestParseMoneyInvalid(t *testing.T) {
+	for _, amount := range []string{"", " ", "-", "+", ".", "-.", "1.23abc", "1.23.4", "abc", "1,50", "1.2x", "--1", "1e", "99999999999999999999"} {
+		if got, err := ParseMoney(amount, "USD"); err == nil {
+			t.Errorf("ParseMoney(%q) = %v, want an error", amount, got)
+		}
+	}
+}
+
+func TestMoneyDecimal(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{NewMoney(1234, "USD"), "12.34"},
+		{NewMoney(5, "USD"), "0.05"},
+		{NewMoney(-5, "USD"), "-0.05"},
+		{NewMoney(0, "CAD"), "0.00"},
+		{NewMoney(1200, "JPY"), "1200"},
+		{NewMoney(1234, "BHD"), "1.234"},
+		{NewMoney(1234, ""), "12.34"},
+	}
+	for _, test := range tests {
+		if got := test.money.Decimal(); got != test.want {
+			t.Errorf("%#v.Decimal() = %q, want %q", test.money, got, test.want)
+		}
+	}
+
+	if got := NewMoney(1234, "USD").String(); got != "12.34 USD" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestMoneyWithCurrency(t *testing.T) {
+	tests := []struct {
+		money    Money
+		currency string
+		want     int64
+	}{
+		{NewMoney(1234, "USD"), "CAD", 1234},
+		{NewMoney(120000, ""), "JPY", 1200},
+		{NewMoney(150, ""), "JPY", 2},
+		{NewMoney(-150, ""), "JPY", -2},
+		{NewMoney(123, ""), "BHD", 1230},
+		{NewMoney(1200, "JPY"), "USD", 120000},
+	}
+	for _, test := range tests {
+		got := test.money.WithCurrency(test.currency)
+		if got.Amount != test.want || got.Currency != test.currency {
+			t.Errorf("%#v.WithCurrency(%s) = %#v, want %d", test.money, test.currency, got, test.want)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	sum, err := NewMoney(1399, "USD").Add(NewMoney(599, "USD"))
+	if err != nil || sum != NewMoney(1998, "USD") {
+		t.Errorf("Add = %v, %v", sum, err)
+	}
+
+	// An amount without a currency adopts the other's
+	difference, err := NewMoney(1000, "").Sub(NewMoney(250, "CAD"))
+	if err != nil || difference != NewMoney(750, "CAD") {
+		t.Errorf("Sub = %v, %v", difference, err)
+	}
+
+	if _, err := NewMoney(100, "USD").Add(NewMoney(100, "CAD")); err == nil {
+		t.Error("adding USD and CAD succeeded")
+	}
+	if cmp, err := NewMoney(100, "USD").Cmp(NewMoney(99, "usd")); err != nil || cmp != 1 {
+		t.Errorf("Cmp = %d, %v", cmp, err)
+	}
+	if got := NewMoney(250, "USD").Mul(3); got != NewMoney(750, "USD") {
+		t.Errorf("Mul = %v", got)
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	encoded, err := json.Marshal(map[string]Money{"Customer": NewMoney(2199, "USD")})
+	if err != nil || string(encoded) != `{"Customer":21.99}` {
+		t.Errorf("Marshal = %s, %v", encoded, err)
+	}
+
+	tests := []struct {
+		json     string
+		currency string
+		want     int64
+	}{
+		{`21.99`, "USD", 2199},
+		{`"21.99"`, "USD", 2199},
+		{`""`, "USD", 0},
+		{`null`, "USD", 0},
+		{`1200`, "JPY", 1200},
+		{`1.234`, "BHD", 1234},
+	}
+	for _, test := range tests {
+		money := Money{Currency: test.currency}
+		if err := json.Unmarshal([]byte(test.json), &money); err != nil {
+			t.Errorf("Unmarshal(%s) failed: %v", test.json, err)
+			continue
+		}
+		if money.Amount != test.want || money.Currency != test.currency {
+			t.Errorf("Unmarshal(%s) = %#v, want %d %s", test.json, money, test.want, test.currency)
+		}
+	}
+
+	var money Money
+	if err := json.Unmarshal([]byte(`"1.2.3"`), &money); err == nil {
+		t.Error("Unmarshal of an invalid amount succeeded")
+	}
+}
+
+func TestOrderAmountsInOrderCurrency(t *testing.T) {
+	tests := []struct {
+		currency string
+		customer interface{}
+		want     int64
+	}{
+		{"USD", 21.99, 2199},
+		{"JPY", 1200.0, 1200},
+		{"BHD", 1.234, 1234},
+		{"BHD", "2.5", 2500},
+	}
+	for _, test := range tests {
+		order := &Order{Currency: test.currency}
+		order.SetFormatted(map[string]interface{}{
+			"Amounts":          map[string]interface{}{"Customer": test.customer},
+			"AmountsBreakdown": map[string]interface{}{"Customer": test.customer, "Bad": "x"},
+		})
+		order.applyCurrency()
+
+		for name, amounts := range map[string]map[string]Money{"Amounts": order.Amounts, "AmountsBreakdown": order.AmountsBreakdown} {
+			if got := amounts["Customer"]; got.Amount != test.want || got.Currency != test.currency {
+				t.Errorf("%s %v %s = %#v, want %d", name, test.customer, test.currency, got, test.want)
+			}
+		}
+		if _, ok := order.AmountsBreakdown["Bad"]; ok {
+			t.Errorf("invalid amount was kept")
+		}
+	}
+}
@@ -2,11 +2,13 @@ package models
 
 import (
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
 )
 
 // NearbyStores represents nearby Domino's Pizza stores
@@ -33,13 +35,12 @@ func NewNearbyStores(address interface{}) (*NearbyStores, error) {
 	street := addr.GetDefaultLineOne()
 	cityStateZip := addr.GetDefaultLineTwo()
 
-	// URL encode the parameters
-	encodedStreet := url.QueryEscape(street)
-	encodedCityStateZip := url.QueryEscape(cityStateZip)
-
-	// Construct the URL directly with the encoded parameters
-	urlStr := fmt.Sprintf("https://order.dominos.com/power/store-locator?s=%s&c=%s&type=Delivery",
-		encodedStreet, encodedCityStateZip)
+	// URL encode the parameters into the current market's store locator
+	urlStr := strings.NewReplacer(
+		"${line1}", url.QueryEscape(street),
+		"${line2}", url.QueryEscape(cityStateZip),
+		"${pickUpType}", "Delivery",
+	).Replace(utils.URLs.Store.Find)
 
 	// Make a direct HTTP request instead of using utils.Get
 	// This ensures we have more control over the headers and request format
@@ -56,8 +57,8 @@ func NewNearbyStores(address interface{}) (*NearbyStores, error) {
 	// Set headers that might be required
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-	req.Header.Set("Origin", "https://order.dominos.com")
-	req.Header.Set("Referer", "https://order.dominos.com/")
+	req.Header.Set("Origin", "https://"+utils.URLs.SourceURI)
+	req.Header.Set("Referer", "https://"+utils.URLs.SourceURI+"/")
 
 	// Send request
 	resp, err := client.Do(req)
@@ -82,7 +83,7 @@ func NewNearbyStores(address interface{}) (*NearbyStores, error) {
 	if storesData, ok := response["Stores"].([]interface{}); ok {
 		for _, storeData := range storesData {
 			if storeMap, ok := storeData.(map[string]interface{}); ok {
-				store := &Store{}
+				store := &Store{market: utils.CurrentMarket()}
 
 				// Set the store ID
 				if storeID, ok := storeMap["StoreID"].(string); ok {
@@ -163,3 +164,13 @@ func (ns *NearbyStores) FindClosestStore(serviceMethod string, isOpen bool) *Sto
 
 	return closestStore
 }
+
+// GetFormatted returns the nearby stores as a map with PascalCase keys
+func (ns *NearbyStores) GetFormatted() map[string]interface{} {
+	return getFormatted(ns)
+}
+
+// SetFormatted updates the nearby stores from a map with keys in any format
+func (ns *NearbyStores) SetFormatted(data map[string]interface{}) {
+	setFormatted(ns, data)
+}
@@ -0,0 +1,242 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// Dietary tags reported in Nutrition.DietaryTags
+const (
+	DietaryVegetarian = "Vegetarian"
+	DietaryVegan      = "Vegan"
+	DietaryGlutenFree = "GlutenFree"
+)
+
+// Nutrition is the nutrition and allergen information of a product or variant
+type Nutrition struct {
+	// CaloriesPerServing is per slice for pizzas. Menus give some as a range such
+	// as "190-290", which sets CaloriesPerServingMax too.
+	CaloriesPerServing    int
+	CaloriesPerServingMax int
+	// Servings per item, such as 8 slices
+	Servings    int
+	ServingUnit string
+	Allergens   []string
+	DietaryTags []string
+}
+
+// ItemNutrition is the nutrition of one item in an order
+type ItemNutrition struct {
+	Code      string
+	Qty       int
+	Nutrition Nutrition
+}
+
+// OrderNutrition summarizes the nutrition of an order
+type OrderNutrition struct {
+	// Calories for the whole order, and the upper end of the range if any item
+	// gives a range
+	Calories    int
+	CaloriesMax int
+	// Allergens found in any item
+	Allergens []string
+	Items     []ItemNutrition
+}
+
+// Calories returns the calories of one item, and the upper end of the range
+func (n Nutrition) Calories() (int, int) {
+	servings := n.Servings
+	if servings < 1 {
+		servings = 1
+	}
+
+	highest := n.CaloriesPerServingMax
+	if highest < n.CaloriesPerServing {
+		highest = n.CaloriesPerServing
+	}
+	return n.CaloriesPerServing * servings, highest * servings
+}
+
+// HasDietaryTag reports whether the menu tagged the product, e.g. with
+// DietaryVegetarian. Untagged products report false even if they qualify.
+func (n Nutrition) HasDietaryTag(tag string) bool {
+	for _, t := range n.DietaryTags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAllergen reports whether the product contains an allergen
+func (n Nutrition) HasAllergen(allergen string) bool {
+	for _, a := range n.Allergens {
+		if strings.EqualFold(a, allergen) {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmpty reports whether no nutrition information was found
+func (n Nutrition) isEmpty() bool {
+	return n.CaloriesPerServing == 0 && n.Servings == 0 && len(n.Allergens) == 0 && len(n.DietaryTags) == 0
+}
+
+// Nutrition returns the product's nutrition and allergen information from the menu
+func (p *Product) Nutrition() Nutrition {
+	return nutritionFrom(p.GetDominosAPIResponse())
+}
+
+// GetVariantNutrition returns a variant's nutrition, filling in anything the
+// variant doesn't list from its product
+func (m *Menu) GetVariantNutrition(variantCode string) (Nutrition, bool) {
+	variant, ok := m.GetVariant(variantCode)
+	if !ok {
+		return Nutrition{}, false
+	}
+
+	nutrition := nutritionFrom(variant)
+	if productCode, ok := variant["ProductCode"].(string); ok {
+		if product, ok := m.GetProduct(productCode); ok {
+			nutrition = mergeNutrition(nutrition, nutritionFrom(product))
+		}
+	}
+	return nutrition, true
+}
+
+// NutritionSummary totals the nutrition of the order's products from the price
+// response, which includes it because NewOrder sets calculateNutrition. Products
+// the response has no nutrition for are looked up on menu if it isn't nil.
+func (o *Order) NutritionSummary(menu *Menu) (*OrderNutrition, error) {
+	if o.priceResponse == nil {
+		return nil, utils.NewDominosPriceError("Order must be priced before summarizing nutrition")
+	}
+
+	// Nutrition Domino's returned for each product, by position
+	priced := make([]map[string]interface{}, 0)
+	if orderData, ok := o.priceResponse["Order"].(map[string]interface{}); ok {
+		products, _ := orderData["Products"].([]interface{})
+		for _, product := range products {
+			data, _ := product.(map[string]interface{})
+			priced = append(priced, data)
+		}
+	}
+
+	summary := &OrderNutrition{Items: make([]ItemNutrition, 0, len(o.Products))}
+	allergens := make(map[string]bool)
+	for i, item := range o.Products {
+		var nutrition Nutrition
+		if i < len(priced) && priced[i] != nil {
+			nutrition = nutritionFrom(priced[i])
+		}
+		if nutrition.isEmpty() && menu != nil {
+			nutrition, _ = menu.GetVariantNutrition(item.Code)
+		}
+
+		qty := item.Qty
+		if qty < 1 {
+			qty = 1
+		}
+		calories, caloriesMax := nutrition.Calories()
+		summary.Calories += calories * qty
+		summary.CaloriesMax += caloriesMax * qty
+		for _, allergen := range nutrition.Allergens {
+			allergens[allergen] = true
+		}
+
+		summary.Items = append(summary.Items, ItemNutrition{Code: item.Code, Qty: qty, Nutrition: nutrition})
+	}
+
+	for allergen := range allergens {
+		summary.Allergens = append(summary.Allergens, allergen)
+	}
+	sort.Strings(summary.Allergens)
+
+	return summary, nil
+}
+
+// nutritionFrom reads the "Nutrition" object of a menu product or variant, or
+// of a product in a price response. This shape is the one the testdata
+// fixtures use; it hasn't been checked against a real response, which
+// TestProductNutritionLive does against a live store:
+//
+//	"Nutrition": {
+//	  "Calories": "190-290",
+//	  "Servings": 8,
+//	  "ServingUnit": "slice",
+//	  "Allergens": ["Milk", "Wheat"],
+//	  "DietaryTags": ["Vegetarian"]
+//	}
+//
+// Calories are per serving, given as a number or a range. Dietary tags are only
+// copied from DietaryTags and never derived from the product, its toppings or
+// its crust, so HasDietaryTag reports false for menus that don't send them.
+func nutritionFrom(data map[string]interface{}) Nutrition {
+	var nutrition Nutrition
+
+	fields, ok := data["Nutrition"].(map[string]interface{})
+	if !ok {
+		return nutrition
+	}
+
+	nutrition.CaloriesPerServing, nutrition.CaloriesPerServingMax = calorieRange(fields["Calories"])
+	nutrition.Servings = intValue(fields["Servings"])
+	nutrition.ServingUnit, _ = fields["ServingUnit"].(string)
+	nutrition.Allergens = stringList(fields["Allergens"])
+	nutrition.DietaryTags = stringList(fields["DietaryTags"])
+	sort.Strings(nutrition.DietaryTags)
+
+	return nutrition
+}
+
+// mergeNutrition fills in what a doesn't have from b
+func mergeNutrition(a Nutrition, b Nutrition) Nutrition {
+	if a.CaloriesPerServing == 0 {
+		a.CaloriesPerServing, a.CaloriesPerServingMax = b.CaloriesPerServing, b.CaloriesPerServingMax
+	}
+	if a.Servings == 0 {
+		a.Servings = b.Servings
+	}
+	if a.ServingUnit == "" {
+		a.ServingUnit = b.ServingUnit
+	}
+	for _, allergen := range b.Allergens {
+		a.Allergens = appendUnique(a.Allergens, allergen)
+	}
+	for _, tag := range b.DietaryTags {
+		a.DietaryTags = appendUnique(a.DietaryTags, tag)
+	}
+	sort.Strings(a.DietaryTags)
+	return a
+}
+
+// calorieRange reads calories given as a number, "210" or a range like "190-290"
+func calorieRange(value interface{}) (int, int) {
+	text, ok := value.(string)
+	if !ok {
+		calories := intValue(value)
+		return calories, calories
+	}
+
+	low, high, isRange := strings.Cut(strings.TrimSpace(text), "-")
+	lowest, _ := strconv.Atoi(strings.TrimSpace(low))
+	if !isRange {
+		return lowest, lowest
+	}
+	highest, _ := strconv.Atoi(strings.TrimSpace(high))
+	return lowest, highest
+}
+
+// appendUnique appends a string unless the list already has it, ignoring case
+func appendUnique(list []string, value string) []string {
+	for _, existing := range list {
+		if strings.EqualFold(existing, value) {
+			return list
+		}
+	}
+	return append(list, value)
+}
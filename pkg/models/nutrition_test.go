@@ -0,0 +1,115 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestProductNutrition(t *testing.T) {
+	menu := &Menu{}
+	menu.SetFormatted(loadFixture(t, "nutrition_menu.json"))
+
+	product, ok := menu.LookupProduct("S_PIZZA")
+	if !ok {
+		t.Fatal("S_PIZZA not found")
+	}
+	want := Nutrition{
+		CaloriesPerServing:    190,
+		CaloriesPerServingMax: 290,
+		Servings:              8,
+		ServingUnit:           "slice",
+		Allergens:             []string{"Milk", "Wheat"},
+		DietaryTags:           []string{DietaryVegetarian},
+	}
+	if got := product.Nutrition(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Nutrition() = %+v, want %+v", got, want)
+	}
+
+	// The variant's calories win and the rest comes from the product
+	nutrition, ok := menu.GetVariantNutrition("14SCREEN")
+	if !ok {
+		t.Fatal("14SCREEN not found")
+	}
+	if nutrition.CaloriesPerServing != 290 || nutrition.CaloriesPerServingMax != 290 || nutrition.Servings != 8 || !nutrition.HasAllergen("milk") {
+		t.Errorf("GetVariantNutrition = %+v", nutrition)
+	}
+	if low, high := nutrition.Calories(); low != 2320 || high != 2320 {
+		t.Errorf("Calories() = %d, %d, want 2320 for 8 slices", low, high)
+	}
+}
+
+func TestNutritionSummary(t *testing.T) {
+	menu := &Menu{}
+	menu.SetFormatted(loadFixture(t, "nutrition_menu.json"))
+
+	order := &Order{Products: []*Item{{Code: "14SCREEN", Qty: 2}, {Code: "2LCOKE", Qty: 1}}}
+	if _, err := order.NutritionSummary(menu); err == nil {
+		t.Error("NutritionSummary before pricing succeeded")
+	}
+
+	order.priceResponse = loadFixture(t, "nutrition_price.json")
+	summary, err := order.NutritionSummary(menu)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two pizzas of 8 slices at 200-300 from the price response, and the
+	// drink's 8 servings of 100 from the menu
+	if summary.Calories != 2*8*200+800 || summary.CaloriesMax != 2*8*300+800 {
+		t.Errorf("Calories = %d-%d, want %d-%d", summary.Calories, summary.CaloriesMax, 2*8*200+800, 2*8*300+800)
+	}
+	if want := []string{"Milk", "Soy", "Wheat"}; !reflect.DeepEqual(summary.Allergens, want) {
+		t.Errorf("Allergens = %v, want %v", summary.Allergens, want)
+	}
+	if len(summary.Items) != 2 || summary.Items[1].Nutrition.ServingUnit != "serving" {
+		t.Errorf("Items = %+v", summary.Items)
+	}
+}
+
+// TestProductNutritionLive checks that a real menu sends the Nutrition objects
+// nutritionFrom reads, and records the menu as testdata/nutrition_live_menu.json
+// when DOMINOS_RECORD is set:
+//
+//	DOMINOS_LIVE_STORE=4336 DOMINOS_RECORD=1 go test ./pkg/models -run Live
+func TestProductNutritionLive(t *testing.T) {
+	storeID := liveStoreID(t)
+	lastResponse := recordResponses(t)
+
+	store, err := NewStore(storeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	menu, err := store.GetMenu("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := lastResponse()
+	var response struct {
+		Products map[string]map[string]interface{}
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatal(err)
+	}
+
+	found := 0
+	for code, data := range response.Products {
+		if _, ok := data["Nutrition"]; !ok {
+			continue
+		}
+		found++
+		product, ok := menu.LookupProduct(code)
+		if !ok {
+			t.Fatalf("%s not found", code)
+		}
+		if product.Nutrition().isEmpty() {
+			t.Errorf("%s has a Nutrition object nutritionFrom can't read: %v", code, data["Nutrition"])
+		}
+	}
+	if found == 0 {
+		t.Errorf("no product in store %s's menu has a Nutrition object", storeID)
+	}
+
+	saveFixture(t, "nutrition_live_menu.json", body)
+}
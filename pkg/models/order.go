@@ -1,6 +1,10 @@
 package models
 
 import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
@@ -10,10 +14,10 @@ import (
 type Order struct {
 	DominosFormat
 	Address               *Address               `json:"address"`
-	Amounts               map[string]interface{} `json:"amounts"`
-	AmountsBreakdown      map[string]interface{} `json:"amountsBreakdown"`
+	Amounts               map[string]Money       `json:"amounts"`
+	AmountsBreakdown      map[string]Money       `json:"amountsBreakdown"`
 	BusinessDate          string                 `json:"businessDate"`
-	Coupons               []interface{}          `json:"coupons"`
+	Coupons               []*Coupon              `json:"coupons"`
 	Currency              string                 `json:"currency"`
 	CustomerID            string                 `json:"customerID"`
 	EstimatedWaitMinutes  string                 `json:"estimatedWaitMinutes"`
@@ -49,23 +53,30 @@ type Order struct {
 	UserAgent             string                 `json:"userAgent"`
 	Version               string                 `json:"version"`
 	FutureOrderTime       string                 `json:"futureOrderTime,omitempty"`
+	// Tip is put on the card payment by AssignPaymentAmounts
+	Tip Money `json:"-"`
+	// TipPercent, when set, recomputes Tip from the food total each time the order is priced
+	TipPercent float64 `json:"-"`
 
 	validationResponse map[string]interface{}
 	priceResponse      map[string]interface{}
 	placeResponse      map[string]interface{}
+	store              *Store
 }
 
 // NewOrder creates a new order with the given customer
 func NewOrder(customer *Customer) *Order {
 	order := &Order{
 		Address:               customer.Address,
-		Coupons:               make([]interface{}, 0),
+		Coupons:               make([]*Coupon, 0),
+		Currency:              utils.CurrentMarket().Currency,
 		Email:                 customer.Email,
 		Extension:             customer.Extension,
 		FirstName:             customer.FirstName,
 		HotspotsLite:          false,
 		LastName:              customer.LastName,
-		LanguageCode:          "en",
+		LanguageCode:          utils.CurrentLanguage(),
+		Market:                utils.URLs.Market,
 		MetaData:              map[string]interface{}{"calculateNutrition": true, "contactless": true},
 		NewUser:               true,
 		NoCombine:             true,
@@ -84,6 +95,10 @@ func NewOrder(customer *Customer) *Order {
 		Version:               "1.0",
 	}
 
+	if order.LanguageCode == "" {
+		order.LanguageCode = "en"
+	}
+
 	return order
 }
 
@@ -106,14 +121,15 @@ func (o *Order) OrderNow() {
 	o.FutureOrderTime = ""
 }
 
-// AddCoupon adds a coupon to the order
-func (o *Order) AddCoupon(coupon interface{}) *Order {
+// AddCoupon adds a coupon to the order. Use Store.GetCoupon for a coupon whose
+// fulfillment rules are checked by ValidateCoupons.
+func (o *Order) AddCoupon(coupon *Coupon) *Order {
 	o.Coupons = append(o.Coupons, coupon)
 	return o
 }
 
-// RemoveCoupon removes a coupon from the order
-func (o *Order) RemoveCoupon(coupon interface{}) *Order {
+// RemoveCoupon removes a coupon previously added to the order
+func (o *Order) RemoveCoupon(coupon *Coupon) *Order {
 	for i, c := range o.Coupons {
 		if c == coupon {
 			o.Coupons = append(o.Coupons[:i], o.Coupons[i+1:]...)
@@ -140,6 +156,64 @@ func (o *Order) RemoveItem(item *Item) *Order {
 	return o
 }
 
+// SetStore sets the store the order is placed with, whose profile is used to
+// check payments before placing
+func (o *Order) SetStore(store *Store) *Order {
+	o.store = store
+	o.StoreID = store.StoreID
+	return o
+}
+
+// CheckPayments validates each payment and checks that the store accepts its type.
+// The store profile is fetched if one hasn't been set with SetStore.
+func (o *Order) CheckPayments() error {
+	for _, payment := range o.Payments {
+		if err := payment.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := o.loadStore(); err != nil {
+		return err
+	}
+
+	for _, payment := range o.Payments {
+		if !o.store.AcceptsPaymentType(payment.Type) {
+			return utils.NewDominosPaymentError("Store " + o.StoreID + " does not accept " + payment.Type + " payments")
+		}
+		if payment.Type == PaymentTypeCreditCard && !payment.IsSavedCard() && !payment.IsTokenized() {
+			if brand := DetectCardBrand(payment.Number); brand != nil && !o.store.AcceptsCardBrand(brand) {
+				return utils.NewDominosPaymentError("Store " + o.StoreID + " does not accept " + brand.Name + " cards")
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateCoupons checks that the order satisfies each of its coupons
+func (o *Order) ValidateCoupons() error {
+	for _, coupon := range o.Coupons {
+		if err := coupon.Validate(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadStore returns the profile of the order's store, fetching it unless it was
+// set with SetStore
+func (o *Order) loadStore() (*Store, error) {
+	if o.store == nil || o.store.StoreID != o.StoreID {
+		store, err := NewStore(o.StoreID)
+		if err != nil {
+			return nil, err
+		}
+		o.store = store
+	}
+	return o.store, nil
+}
+
 // GetValidationResponse returns the response from the last validation
 func (o *Order) GetValidationResponse() map[string]interface{} {
 	return o.validationResponse
@@ -155,6 +229,118 @@ func (o *Order) GetPlaceResponse() map[string]interface{} {
 	return o.placeResponse
 }
 
+// Total returns the amount the customer pays, available once the order is priced
+func (o *Order) Total() Money {
+	if total, ok := o.AmountsBreakdown["Customer"]; ok {
+		return total
+	}
+	if total, ok := o.Amounts["Customer"]; ok {
+		return total
+	}
+	return Money{Currency: o.Currency}
+}
+
+// update merges an order returned by the API into o. The caller's payments are
+// kept, since responses don't echo card details back, and so are its coupons,
+// updated with the status Domino's reports.
+func (o *Order) update(orderData map[string]interface{}) {
+	payments := o.Payments
+	coupons := o.Coupons
+	o.Coupons = nil // decode into new coupons rather than the caller's
+	o.SetFormatted(orderData)
+	o.Payments = payments
+	o.Coupons = mergeCoupons(coupons, o.Coupons)
+
+	o.applyCurrency()
+	o.applyTip()
+}
+
+// clone returns a copy of the order that can be priced without changing o.
+// Responses are decoded into existing items and maps, so those are copied too.
+func (o *Order) clone() *Order {
+	c := *o
+
+	if o.Address != nil {
+		address := *o.Address
+		c.Address = &address
+	}
+
+	c.Products = make([]*Item, len(o.Products))
+	for i, item := range o.Products {
+		copied := *item
+		copied.Options = copyMap(item.Options)
+		c.Products[i] = &copied
+	}
+	c.Coupons = make([]*Coupon, len(o.Coupons))
+	for i, coupon := range o.Coupons {
+		copied := *coupon
+		c.Coupons[i] = &copied
+	}
+	c.Payments = make([]*Payment, len(o.Payments))
+	for i, payment := range o.Payments {
+		copied := *payment
+		c.Payments[i] = &copied
+	}
+
+	c.Amounts = make(map[string]Money, len(o.Amounts))
+	for key, amount := range o.Amounts {
+		c.Amounts[key] = amount
+	}
+	c.AmountsBreakdown = make(map[string]Money, len(o.AmountsBreakdown))
+	for key, amount := range o.AmountsBreakdown {
+		c.AmountsBreakdown[key] = amount
+	}
+	c.MetaData = copyMap(o.MetaData)
+	c.Partners = copyMap(o.Partners)
+	c.Promotions = copyMap(o.Promotions)
+	c.Tags = copyMap(o.Tags)
+
+	return &c
+}
+
+// copyMap deep copies a map decoded from JSON
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+
+	data, _ := json.Marshal(m)
+	var copied map[string]interface{}
+	json.Unmarshal(data, &copied)
+	return copied
+}
+
+// applyCurrency labels the amounts Domino's returned with the order's currency,
+// falling back to the current market's, and fills it in on payments and the tip
+// when they have none. Amounts already in a currency keep it.
+func (o *Order) applyCurrency() {
+	if o.Currency == "" {
+		o.Currency = utils.CurrentMarket().Currency
+	}
+
+	for key, amount := range o.Amounts {
+		o.Amounts[key] = amount.WithCurrency(o.Currency)
+	}
+	for key, amount := range o.AmountsBreakdown {
+		o.AmountsBreakdown[key] = amount.WithCurrency(o.Currency)
+	}
+	for _, payment := range o.Payments {
+		labelCurrency(&payment.Amount, o.Currency)
+		labelCurrency(&payment.TipAmount, o.Currency)
+		if payment.MaxAmount != nil {
+			labelCurrency(payment.MaxAmount, o.Currency)
+		}
+	}
+	labelCurrency(&o.Tip, o.Currency)
+}
+
+// labelCurrency fills in the currency of an amount that has none
+func labelCurrency(amount *Money, currency string) {
+	if amount.Currency == "" {
+		amount.Currency = currency
+	}
+}
+
 // Validate validates the order with Domino's API
 func (o *Order) Validate() error {
 	if o.StoreID == "" {
@@ -203,7 +389,7 @@ func (o *Order) Validate() error {
 
 	// Update order with validated data
 	if orderData, ok := response["Order"].(map[string]interface{}); ok {
-		o.SetFormatted(orderData)
+		o.update(orderData)
 	}
 
 	return nil
@@ -211,6 +397,11 @@ func (o *Order) Validate() error {
 
 // Price gets the price for the order from Domino's API
 func (o *Order) Price() error {
+	return o.PriceContext(context.Background())
+}
+
+// PriceContext is like Price but aborts the request when ctx is done
+func (o *Order) PriceContext(ctx context.Context) error {
 	if o.StoreID == "" {
 		return utils.NewDominosStoreError("Store ID must be set before pricing an order")
 	}
@@ -244,7 +435,7 @@ func (o *Order) Price() error {
 	payload["Order"].(map[string]interface{})["Payments"] = payments
 
 	// Send price request
-	response, err := utils.Post(utils.URLs.Order.Price, payload)
+	response, err := utils.PostContext(ctx, utils.URLs.Order.Price, payload)
 	if err != nil {
 		return err
 	}
@@ -261,28 +452,45 @@ func (o *Order) Price() error {
 
 	// Update order with priced data
 	if orderData, ok := response["Order"].(map[string]interface{}); ok {
-		o.SetFormatted(orderData)
+		o.update(orderData)
 	}
 
 	return nil
 }
 
-// Place places the order with Domino's API
-func (o *Order) Place() error {
+// Place places the order with Domino's API and returns a tracking handle
+// pre-populated from the place response
+func (o *Order) Place() (*Tracking, error) {
 	if o.StoreID == "" {
-		return utils.NewDominosStoreError("Store ID must be set before placing an order")
+		return nil, utils.NewDominosStoreError("Store ID must be set before placing an order")
 	}
 
 	if len(o.Products) == 0 {
-		return utils.NewDominosProductsError("Order must contain product items before placing")
+		return nil, utils.NewDominosProductsError("Order must contain product items before placing")
 	}
 
 	if o.Address == nil || o.Address.Region == "" {
-		return utils.NewDominosAddressError("Order must have a valid address before placing")
+		return nil, utils.NewDominosAddressError("Order must have a valid address before placing")
 	}
 
 	if len(o.Payments) == 0 {
-		return utils.NewDominosProductsError("Order must have at least one payment method")
+		return nil, utils.NewDominosProductsError("Order must have at least one payment method")
+	}
+
+	if err := o.ValidateCoupons(); err != nil {
+		return nil, err
+	}
+
+	if err := o.CheckPayments(); err != nil {
+		return nil, err
+	}
+
+	if err := o.CheckPaymentTotals(); err != nil {
+		return nil, err
+	}
+
+	if err := o.CheckTip(); err != nil {
+		return nil, err
 	}
 
 	// Create payload
@@ -310,7 +518,7 @@ func (o *Order) Place() error {
 	// Send place order request
 	response, err := utils.Post(utils.URLs.Order.Place, payload)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	o.placeResponse = response
@@ -320,13 +528,90 @@ func (o *Order) Place() error {
 	responseStatus, respOk := response["Status"]
 
 	if (ok && orderStatus.(float64) == -1) || (respOk && responseStatus.(float64) == -1) {
-		return utils.NewDominosPlaceOrderError(response)
+		return nil, utils.NewDominosPlaceOrderError(response)
 	}
 
 	// Update order with placed data
-	if orderData, ok := response["Order"].(map[string]interface{}); ok {
-		o.SetFormatted(orderData)
+	orderData, _ := response["Order"].(map[string]interface{})
+	if orderData != nil {
+		o.update(orderData)
 	}
 
-	return nil
+	return o.newPlacedTracking(orderData), nil
+}
+
+// newPlacedTracking builds a tracking handle for a placed order
+func (o *Order) newPlacedTracking(orderData map[string]interface{}) *Tracking {
+	tracking := NewTracking()
+	tracking.OrderID = o.OrderID
+	tracking.Phone = sanitizePhone(o.Phone)
+	tracking.ServiceMethod = o.ServiceMethod
+	tracking.StoreID = o.StoreID
+	tracking.PulseOrderGUID = o.PulseOrderGuid
+	tracking.Market = o.Market
+	tracking.Language = o.LanguageCode
+
+	// The store's order key is reported as StoreOrderID in the place response
+	for _, key := range []string{"StoreOrderID", "OrderKey"} {
+		if orderKey, ok := orderData[key].(string); ok && orderKey != "" {
+			tracking.OrderKey = orderKey
+			break
+		}
+	}
+	if storeID, ok := orderData["StoreID"].(string); ok && storeID != "" {
+		tracking.StoreID = storeID
+	}
+
+	tracking.SetDominosAPIResponse(orderData)
+
+	return tracking
+}
+
+// GetFormatted returns the order as a map with PascalCase keys
+func (o *Order) GetFormatted() map[string]interface{} {
+	return getFormatted(o)
+}
+
+// SetFormatted updates the order from a map with keys in any format.
+// Nested keys such as product codes are left untouched.
+func (o *Order) SetFormatted(data map[string]interface{}) {
+	setFormattedShallow(o, data)
+
+	// Amounts decode into new map entries that can't know the order's currency,
+	// so they are parsed again once it is known
+	if o.Currency == "" {
+		o.Currency = utils.CurrentMarket().Currency
+	}
+	if amounts, ok := lookupKey(data, "Amounts").(map[string]interface{}); ok {
+		o.Amounts = parseAmounts(amounts, o.Currency)
+	}
+	if amounts, ok := lookupKey(data, "AmountsBreakdown").(map[string]interface{}); ok {
+		o.AmountsBreakdown = parseAmounts(amounts, o.Currency)
+	}
+}
+
+// parseAmounts converts amounts decoded from JSON to Money, skipping any that
+// aren't numbers
+func parseAmounts(amounts map[string]interface{}, currency string) map[string]Money {
+	parsed := make(map[string]Money, len(amounts))
+	for key, value := range amounts {
+		var text string
+		switch value := value.(type) {
+		case float64:
+			text = strconv.FormatFloat(value, 'f', -1, 64)
+		case string:
+			text = value
+		case json.Number:
+			text = value.String()
+		default:
+			continue
+		}
+
+		if strings.TrimSpace(text) == "" {
+			parsed[key] = Money{Currency: currency}
+		} else if amount, err := ParseMoney(text, currency); err == nil {
+			parsed[key] = amount
+		}
+	}
+	return parsed
 }
@@ -0,0 +1,484 @@
+package models
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Results whose scores are closer than this are too close to choose between
+const parseAmbiguityMargin = 0.05
+
+// Where phrases of an order are split
+var orderPhraseSplit = regexp.MustCompile(`[,;\n]|\band\b|\bplus\b|&|\bthen\b|\balso\b`)
+
+// "and" or "&" between two words
+var orderNameJoin = regexp.MustCompile(`\s*(\band\b|&)\s*`)
+
+// Quantity words at the start of a phrase
+var quantityWords = map[string]int{
+	"a": 1, "an": 1, "one": 1, "single": 1, "two": 2, "couple": 2, "pair": 2, "three": 3,
+	"four": 4, "five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+	"eleven": 11, "twelve": 12, "dozen": 12,
+}
+
+// Words after a number that make it part of a size, as in "2 liter" or "14 piece"
+var quantityUnits = map[string]bool{
+	"liter": true, "litre": true, "l": true, "oz": true, "ounce": true, "piece": true,
+	"pc": true, "inch": true, "in": true,
+}
+
+// Topping amounts set by the word before a topping
+var toppingAmountWords = map[string]string{
+	"extra": "1.5", "more": "1.5", "double": "2", "light": "0.5", "easy": "0.5",
+	"less": "0.5", "no": "0", "without": "0", "hold": "0",
+}
+
+// Parts of a pizza set by the words after a topping
+var toppingPortionWords = map[string]string{
+	"left": "1/2", "right": "2/2", "whole": "1/1", "all": "1/1",
+}
+
+// ParsedOrder is the result of parsing an order from text
+type ParsedOrder struct {
+	Items []*Item
+	// Ambiguities are the phrases that weren't turned into items or options
+	Ambiguities []OrderAmbiguity
+}
+
+// OrderAmbiguity is a phrase the parser couldn't resolve on its own
+type OrderAmbiguity struct {
+	Phrase string
+	Reason string
+	// Candidates are the products the phrase could mean, if any
+	Candidates []MenuSearchResult
+}
+
+// OrderParser turns text such as "two large pepperoni pizzas and a 2-liter coke"
+// into order items. It never guesses: anything it can't resolve is reported as
+// an ambiguity.
+type OrderParser struct {
+	Search *MenuSearch
+	// AssumeDefaultSize picks the search's default size when a phrase names none,
+	// instead of reporting it as ambiguous
+	AssumeDefaultSize bool
+}
+
+// orderPhrase is a phrase of an order split into its parts
+type orderPhrase struct {
+	text     string
+	qty      int
+	hasQty   bool
+	query    []string
+	toppings []toppingMention
+	// unknown are the words after "with" that aren't toppings
+	unknown []string
+}
+
+// toppingMention is a topping named in a phrase with its amount and portion
+type toppingMention struct {
+	name     string
+	tokens   []string
+	amount   string
+	portion  string
+	halfOnly bool // "half" without saying which
+}
+
+// NewOrderParser creates an order parser for a menu
+func NewOrderParser(menu *Menu) *OrderParser {
+	return &OrderParser{Search: NewMenuSearch(menu)}
+}
+
+// Parse turns text into items, reporting what it couldn't resolve
+func (p *OrderParser) Parse(text string) *ParsedOrder {
+	parsed := &ParsedOrder{Items: make([]*Item, 0), Ambiguities: make([]OrderAmbiguity, 0)}
+
+	var last *Item
+	var lastCategory string
+	var carried []toppingMention
+	// Toppings each item has been given by the text, as opposed to its recipe
+	mentioned := make(map[*Item]map[string]bool)
+	for _, phrase := range p.splitPhrases(text) {
+		phrase.toppings = append(carried, phrase.toppings...)
+		carried = nil
+
+		// Toppings alone change the previous item, or the next if there is none
+		if !phrase.hasQty && len(phrase.query) > 0 && last != nil && len(p.Search.Search(strings.Join(phrase.query, " "), 1)) == 0 {
+			phrase.unknown, phrase.query = append(phrase.unknown, phrase.query...), nil
+		}
+		if !phrase.hasQty && len(phrase.query) == 0 {
+			if last == nil {
+				carried = phrase.toppings
+				continue
+			}
+			parsed.Ambiguities = append(parsed.Ambiguities, p.applyToppings(last, lastCategory, phrase, mentioned)...)
+			continue
+		}
+
+		item, category, ambiguities := p.parseItem(phrase, mentioned)
+		parsed.Ambiguities = append(parsed.Ambiguities, ambiguities...)
+		if item != nil {
+			parsed.Items = append(parsed.Items, item)
+			last, lastCategory = item, category
+		}
+	}
+
+	for _, mention := range carried {
+		parsed.Ambiguities = append(parsed.Ambiguities, OrderAmbiguity{Phrase: mention.name, Reason: "No item to put " + mention.name + " on"})
+	}
+
+	return parsed
+}
+
+// splitPhrases splits text into phrases and the parts of each
+func (p *OrderParser) splitPhrases(text string) []*orderPhrase {
+	// "and" in a product name, as in "mac and cheese", doesn't split phrases
+	text = strings.ToLower(text)
+	for _, join := range p.Search.nameJoins() {
+		text = join.ReplaceAllStringFunc(text, func(match string) string {
+			return orderNameJoin.ReplaceAllString(match, " ")
+		})
+	}
+
+	phrases := make([]*orderPhrase, 0)
+	for _, part := range orderPhraseSplit.Split(text, -1) {
+		tokens := p.Search.tokenize(part)
+		if len(tokens) == 0 {
+			continue
+		}
+
+		phrase := &orderPhrase{text: strings.TrimSpace(part), qty: 1}
+		if qty, ok := phraseQuantity(tokens); ok {
+			phrase.qty, phrase.hasQty = qty, true
+			tokens = tokens[1:]
+		}
+
+		amount := ""
+		with := false
+		// Portions only reach back to the toppings named since the last
+		// product word or "with", so "pepperoni pizza with cheese on the
+		// left" doesn't halve the pepperoni
+		clause := 0
+		for i := 0; i < len(tokens); i++ {
+			token := tokens[i]
+			if value, ok := toppingAmountWords[token]; ok {
+				amount = value
+				continue
+			}
+
+			if topping, length := p.Search.toppingAt(tokens[i:]); length > 0 {
+				if amount == "" {
+					amount = "1"
+				}
+				phrase.toppings = append(phrase.toppings, toppingMention{name: strings.Join(tokens[i:i+length], " "), tokens: topping, amount: amount})
+				amount = ""
+				i += length - 1
+				continue
+			}
+
+			if portion, ok := toppingPortionWords[token]; ok {
+				setPortion(phrase.toppings[clause:], portion, false)
+				continue
+			}
+			if token == "half" {
+				setPortion(phrase.toppings[clause:], "", true)
+				continue
+			}
+			if token == "with" {
+				with = true
+				clause = len(phrase.toppings)
+				continue
+			}
+			if token == "on" || token == "side" || searchStopWords[token] {
+				continue
+			}
+
+			if with && !p.Search.isMenuWord(token) {
+				phrase.unknown = append(phrase.unknown, token)
+				continue
+			}
+			phrase.query = append(phrase.query, token)
+			clause = len(phrase.toppings)
+		}
+
+		phrases = append(phrases, phrase)
+	}
+	return phrases
+}
+
+// parseItem resolves a phrase to an item
+func (p *OrderParser) parseItem(phrase *orderPhrase, mentioned map[*Item]map[string]bool) (*Item, string, []OrderAmbiguity) {
+	// Search with the toppings too, since they help rank products
+	query := strings.Join(phrase.query, " ")
+	for _, mention := range phrase.toppings {
+		if mention.amount != "0" {
+			query += " " + strings.Join(mention.tokens, " ")
+		}
+	}
+
+	results := p.Search.Search(query, 5)
+	if len(results) == 0 {
+		return nil, "", []OrderAmbiguity{{Phrase: phrase.text, Reason: "No product matches"}}
+	}
+	if len(results) > 1 && results[0].Score-results[1].Score < parseAmbiguityMargin && results[0].ProductCode != results[1].ProductCode {
+		return nil, "", []OrderAmbiguity{{Phrase: phrase.text, Reason: "Several products match", Candidates: closeResults(results)}}
+	}
+
+	best := results[0]
+	product := p.Search.product(best.ProductCode)
+	parsedQuery := p.Search.ParseQuery(query)
+	if parsedQuery.Size == "" && !p.AssumeDefaultSize && p.Search.hasSeveralSizes(product) {
+		return nil, "", []OrderAmbiguity{{Phrase: phrase.text, Reason: "No size given for " + best.ProductName, Candidates: []MenuSearchResult{best}}}
+	}
+
+	item, err := NewItem(map[string]interface{}{"code": best.VariantCode, "qty": phrase.qty})
+	if err != nil {
+		return nil, "", []OrderAmbiguity{{Phrase: phrase.text, Reason: err.Error()}}
+	}
+
+	// Options start from the recipe so removals and halves are explicit
+	for _, code := range sortedKeys(product.recipe) {
+		item.Options[code] = map[string]interface{}{"1/1": recipeAmount(product, code)}
+	}
+
+	ambiguities := p.applyToppings(item, product.category, phrase, mentioned)
+	return item, product.category, ambiguities
+}
+
+// applyToppings sets the options of the toppings named in a phrase on an item.
+// A half of a topping the text already named is added to what it has, so
+// "pepperoni on the left and extra pepperoni on the right" keeps both halves,
+// as does a new amount on half of a recipe topping; otherwise the mention
+// replaces the recipe's amount.
+func (p *OrderParser) applyToppings(item *Item, category string, phrase *orderPhrase, mentioned map[*Item]map[string]bool) []OrderAmbiguity {
+	if mentioned[item] == nil {
+		mentioned[item] = make(map[string]bool)
+	}
+
+	ambiguities := make([]OrderAmbiguity, 0)
+	product := p.Search.variantProduct(item.Code)
+	if len(phrase.unknown) > 0 {
+		ambiguities = append(ambiguities, OrderAmbiguity{Phrase: phrase.text, Reason: strings.Join(phrase.unknown, " ") + " isn't on the menu"})
+	}
+
+	for _, mention := range phrase.toppings {
+		if mention.halfOnly {
+			ambiguities = append(ambiguities, OrderAmbiguity{Phrase: phrase.text, Reason: "Which half should have " + mention.name + "?"})
+			continue
+		}
+
+		code := p.Search.toppingCode(category, mention.tokens)
+		if (code == "" || (product != nil && !product.toppings[code])) && product != nil && product.named(mention.tokens) {
+			// Part of the product's name, like "chicken" in "chicken wings"
+			continue
+		}
+		if code == "" || (product != nil && !product.toppings[code]) {
+			ambiguities = append(ambiguities, OrderAmbiguity{Phrase: phrase.text, Reason: mention.name + " isn't available on this item"})
+			continue
+		}
+
+		if mention.amount == "0" {
+			// Domino's puts back recipe toppings that are left out
+			if product != nil && product.recipe[code] {
+				item.Options[code] = map[string]interface{}{"1/1": "0"}
+			} else {
+				delete(item.Options, code)
+			}
+			continue
+		}
+
+		portion := mention.portion
+		if portion == "" {
+			portion = "1/1"
+		}
+		// A new amount on half of a recipe topping keeps the recipe on the
+		// other half, as in "extra cheese on the left"
+		options, ok := item.Options[code].(map[string]interface{})
+		keep := mentioned[item][code] || (product != nil && product.recipe[code] && mention.amount != recipeAmount(product, code))
+		if portion == "1/1" || !ok || !keep {
+			item.Options[code] = map[string]interface{}{portion: mention.amount}
+		} else {
+			if whole, ok := options["1/1"]; ok {
+				delete(options, "1/1")
+				options["1/2"], options["2/2"] = whole, whole
+			}
+			options[portion] = mention.amount
+		}
+		mentioned[item][code] = true
+	}
+
+	return ambiguities
+}
+
+// phraseQuantity reads the quantity a phrase starts with
+func phraseQuantity(tokens []string) (int, bool) {
+	if qty, ok := quantityWords[tokens[0]]; ok {
+		return qty, true
+	}
+
+	qty, err := strconv.Atoi(tokens[0])
+	if err != nil || qty < 1 {
+		return 0, false
+	}
+	if len(tokens) > 1 && quantityUnits[tokens[1]] {
+		return 0, false
+	}
+	return qty, true
+}
+
+// setPortion puts the toppings named in the current clause without a portion on
+// a part of the pizza
+func setPortion(mentions []toppingMention, portion string, halfOnly bool) {
+	for i := len(mentions) - 1; i >= 0 && mentions[i].portion == "" && !mentions[i].halfOnly; i-- {
+		mentions[i].portion = portion
+		mentions[i].halfOnly = halfOnly
+	}
+	// "left half" names the half after "half" was seen
+	for i := range mentions {
+		if mentions[i].halfOnly && portion != "" {
+			mentions[i].portion, mentions[i].halfOnly = portion, false
+		}
+	}
+}
+
+// closeResults returns the results scoring close to the best
+func closeResults(results []MenuSearchResult) []MenuSearchResult {
+	close := make([]MenuSearchResult, 0, len(results))
+	for _, result := range results {
+		if results[0].Score-result.Score < parseAmbiguityMargin {
+			close = append(close, result)
+		}
+	}
+	return close
+}
+
+// recipeAmount returns the amount of a default topping, such as "1" in "X=1"
+func recipeAmount(product *searchProduct, code string) string {
+	for _, option := range strings.Split(product.defaultToppings, ",") {
+		if optionCode, amount, ok := strings.Cut(option, "="); ok && strings.TrimSpace(optionCode) == code {
+			return strings.TrimSpace(amount)
+		}
+	}
+	return "1"
+}
+
+// product returns an indexed product by code
+func (s *MenuSearch) product(code string) *searchProduct {
+	for _, product := range s.products {
+		if product.code == code {
+			return product
+		}
+	}
+	return nil
+}
+
+// variantProduct returns the indexed product a variant belongs to
+func (s *MenuSearch) variantProduct(variantCode string) *searchProduct {
+	for _, product := range s.products {
+		for _, code := range product.variants {
+			if code == variantCode {
+				return product
+			}
+		}
+	}
+	return nil
+}
+
+// named reports whether any of the tokens is in the product's or a variant's name
+func (p *searchProduct) named(tokens []string) bool {
+	for _, field := range p.fields {
+		if field.weight < 0.8 {
+			continue
+		}
+		for _, token := range tokens {
+			if containsTokens(field.tokens, []string{token}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nameJoins returns patterns matching the words either side of "and" or "&" in
+// product and variant names, such as "mac and cheese" in "5-Cheese Mac & Cheese"
+func (s *MenuSearch) nameJoins() []*regexp.Regexp {
+	patterns := make(map[string]bool)
+	for _, product := range s.products {
+		names := []string{product.name}
+		for _, code := range product.variants {
+			if variant, ok := s.menu.GetVariant(code); ok {
+				names = append(names, menuText(variant["Name"]))
+			}
+		}
+
+		for _, name := range names {
+			words := cleanWords(strings.ReplaceAll(name, "&", " and "))
+			for i := 1; i+1 < len(words); i++ {
+				if words[i] == "and" && words[i-1] != "and" && words[i+1] != "and" {
+					patterns[`\b`+regexp.QuoteMeta(words[i-1])+`\s*(?:\band\b|&)\s*`+regexp.QuoteMeta(words[i+1])+`\b`] = true
+				}
+			}
+		}
+	}
+
+	joins := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range sortedKeys(patterns) {
+		joins = append(joins, regexp.MustCompile(pattern))
+	}
+	return joins
+}
+
+// hasSeveralSizes reports whether a product's variants come in more than one size
+func (s *MenuSearch) hasSeveralSizes(product *searchProduct) bool {
+	sizes := make(map[string]bool)
+	for _, code := range product.variants {
+		if variant, ok := s.menu.GetVariant(code); ok {
+			size, _ := variant["SizeCode"].(string)
+			sizes[size] = true
+		}
+	}
+	return len(sizes) > 1
+}
+
+// toppingAt returns the name tokens of the topping whose name starts tokens, and
+// how many tokens it spans
+func (s *MenuSearch) toppingAt(tokens []string) ([]string, int) {
+	for _, topping := range s.toppings {
+		if len(topping.tokens) <= len(tokens) && containsTokens(tokens[:len(topping.tokens)], topping.tokens) {
+			return topping.tokens, len(topping.tokens)
+		}
+	}
+
+	// A single word of a longer name, like "sausage" for "Italian Sausage"
+	for _, topping := range s.toppings {
+		if len(topping.tokens) > 1 && containsTokens(topping.tokens, tokens[:1]) && !searchStopWords[tokens[0]] {
+			return topping.tokens, 1
+		}
+	}
+	return nil, 0
+}
+
+// toppingCode returns the code of the topping with these name tokens in a category
+func (s *MenuSearch) toppingCode(category string, tokens []string) string {
+	for _, topping := range s.toppings {
+		if strings.EqualFold(topping.category, category) && strings.Join(topping.tokens, " ") == strings.Join(tokens, " ") {
+			return topping.code
+		}
+	}
+	return ""
+}
+
+// isMenuWord reports whether a word names a size, a crust or part of a product
+func (s *MenuSearch) isMenuWord(word string) bool {
+	if s.isCategoryWord(s.sizes, word) || s.isCategoryWord(s.crusts, word) {
+		return true
+	}
+	for _, product := range s.products {
+		for _, field := range product.fields {
+			if containsTokens(field.tokens, []string{word}) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOrderParser(t *testing.T) {
+	parser := NewOrderParser(loadMenu(t))
+
+	type item struct {
+		code    string
+		qty     int
+		options map[string]interface{}
+	}
+	whole := func(amount string) map[string]interface{} { return map[string]interface{}{"1/1": amount} }
+
+	tests := []struct {
+		text  string
+		items []item
+	}{
+		{"two large pepperoni pizzas and a coke", []item{
+			{"14SCREEN", 2, map[string]interface{}{"X": whole("1"), "C": whole("1"), "P": whole("1")}},
+			{"2LCOKE", 1, map[string]interface{}{}},
+		}},
+		{"a mac and cheese", []item{
+			{"PINPASMC", 1, map[string]interface{}{}},
+		}},
+		{"mac & cheese and a coke", []item{
+			{"PINPASMC", 1, map[string]interface{}{}},
+			{"2LCOKE", 1, map[string]interface{}{}},
+		}},
+		{"a large spinach & feta pizza and 2 medium spinach and feta", []item{
+			{"14SCSPF", 1, map[string]interface{}{"C": whole("1")}},
+			{"12SCSPF", 2, map[string]interface{}{"C": whole("1")}},
+		}},
+		{"large thin pizza with no cheese", []item{
+			{"14THIN", 1, map[string]interface{}{"X": whole("1"), "C": whole("0")}},
+		}},
+		{"large pizza with pepperoni on the left and extra pepperoni on the right", []item{
+			{"14SCREEN", 1, map[string]interface{}{"X": whole("1"), "C": whole("1"), "P": map[string]interface{}{"1/2": "1", "2/2": "1.5"}}},
+		}},
+		{"large pizza with pepperoni, light pepperoni on the right", []item{
+			{"14SCREEN", 1, map[string]interface{}{"X": whole("1"), "C": whole("1"), "P": map[string]interface{}{"1/2": "1", "2/2": "0.5"}}},
+		}},
+		{"large hawaiian with ham on the left", []item{
+			{"14SCUH", 1, map[string]interface{}{"X": whole("1"), "C": whole("1"), "H": map[string]interface{}{"1/2": "1"}, "N": whole("1")}},
+		}},
+		{"large pepperoni pizza with extra cheese on the left half", []item{
+			{"14SCREEN", 1, map[string]interface{}{"X": whole("1"), "C": map[string]interface{}{"1/2": "1.5", "2/2": "1"}, "P": whole("1")}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			parsed := parser.Parse(tt.text)
+			if len(parsed.Ambiguities) > 0 {
+				t.Errorf("ambiguities = %+v", parsed.Ambiguities)
+			}
+			if len(parsed.Items) != len(tt.items) {
+				t.Fatalf("got %d items, want %d", len(parsed.Items), len(tt.items))
+			}
+			for i, want := range tt.items {
+				got := parsed.Items[i]
+				if got.Code != want.code || got.Qty != want.qty {
+					t.Errorf("item %d = %d x %s, want %d x %s", i, got.Qty, got.Code, want.qty, want.code)
+				}
+				if !reflect.DeepEqual(got.Options, want.options) {
+					t.Errorf("item %d options = %v, want %v", i, got.Options, want.options)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderParserAmbiguities(t *testing.T) {
+	parser := NewOrderParser(loadMenu(t))
+
+	tests := []struct {
+		text   string
+		reason string
+	}{
+		{"a pizza", "No size given"},
+		{"large pizza with pepperoni on half", "Which half"},
+		{"large pizza with anchovies", "anchovy isn't on the menu"},
+		{"sushi", "No product matches"},
+		{"pepperoni", "No item to put"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			parsed := parser.Parse(tt.text)
+			if len(parsed.Ambiguities) != 1 || !strings.Contains(parsed.Ambiguities[0].Reason, tt.reason) {
+				t.Errorf("ambiguities = %+v, want one containing %q", parsed.Ambiguities, tt.reason)
+			}
+		})
+	}
+
+	parser.AssumeDefaultSize = true
+	parsed := parser.Parse("a pizza")
+	if len(parsed.Ambiguities) != 0 || len(parsed.Items) != 1 || parsed.Items[0].Code != "14SCREEN" {
+		t.Errorf("with AssumeDefaultSize got %+v", parsed)
+	}
+}
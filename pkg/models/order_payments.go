@@ -0,0 +1,193 @@
+package models
+
+import (
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// AddPayment adds a payment to the order. Payments with a MaxAmount, such as gift
+// cards, are charged first in the order they were added, and a single payment
+// without one pays the rest. A payment in another currency is rejected, since
+// its amounts can't be converted.
+func (o *Order) AddPayment(payment *Payment) error {
+	o.applyCurrency()
+	if err := o.checkPaymentCurrency(payment); err != nil {
+		return err
+	}
+
+	o.Payments = append(o.Payments, payment)
+	o.applyCurrency()
+	return nil
+}
+
+// checkPaymentCurrency checks that a payment's amounts are in the order's currency
+func (o *Order) checkPaymentCurrency(payment *Payment) error {
+	amounts := []Money{payment.Amount, payment.TipAmount}
+	if payment.MaxAmount != nil {
+		amounts = append(amounts, *payment.MaxAmount)
+	}
+
+	for _, amount := range amounts {
+		if err := o.checkCurrency(amount, "A "+payment.Type+" payment"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkCurrency checks that an amount is in the order's currency, describing
+// what the amount is for in the error
+func (o *Order) checkCurrency(amount Money, description string) error {
+	if amount.Currency != "" && o.Currency != "" && amount.Currency != o.Currency {
+		return utils.NewDominosCurrencyError(description + " in " + amount.Currency + " can't be used on an order in " + o.Currency)
+	}
+	return nil
+}
+
+// RemovePayment removes a payment from the order
+func (o *Order) RemovePayment(payment *Payment) *Order {
+	for i, p := range o.Payments {
+		if p == payment {
+			o.Payments = append(o.Payments[:i], o.Payments[i+1:]...)
+			break
+		}
+	}
+	return o
+}
+
+// SetTip sets a fixed tip, which AssignPaymentAmounts puts on the card payment.
+// A tip in a currency other than the order's is rejected.
+func (o *Order) SetTip(tip Money) error {
+	o.applyCurrency()
+	if err := o.checkCurrency(tip, "A tip"); err != nil {
+		return err
+	}
+
+	o.Tip = tip
+	o.TipPercent = 0
+	o.applyCurrency()
+	o.applyTip()
+	return nil
+}
+
+// isPriced reports whether the order has a total from the Price endpoint
+func (o *Order) isPriced() bool {
+	if _, ok := o.AmountsBreakdown["Customer"]; ok {
+		return true
+	}
+	_, ok := o.Amounts["Customer"]
+	return ok
+}
+
+// AssignPaymentAmounts splits the priced total across the order's payments and
+// puts the tip on the last credit card payment. Call it after Price.
+func (o *Order) AssignPaymentAmounts() error {
+	if !o.isPriced() {
+		return utils.NewDominosPaymentError("Order must be priced before assigning payment amounts")
+	}
+	if len(o.Payments) == 0 {
+		return utils.NewDominosPaymentError("Order has no payments to assign amounts to")
+	}
+	o.applyCurrency()
+	if err := o.checkCurrency(o.Tip, "A tip"); err != nil {
+		return err
+	}
+	for _, payment := range o.Payments {
+		if err := o.checkPaymentCurrency(payment); err != nil {
+			return err
+		}
+	}
+
+	// Find the payment that covers the remaining balance and the one that takes the tip
+	var remainder, tipped *Payment
+	for _, payment := range o.Payments {
+		if payment.MaxAmount == nil {
+			if remainder != nil {
+				return utils.NewDominosPaymentError("Only one payment without a MaxAmount can pay the remaining balance")
+			}
+			remainder = payment
+		}
+		if payment.Type == PaymentTypeCreditCard {
+			tipped = payment
+		}
+	}
+	if !o.Tip.IsZero() && tipped == nil {
+		return utils.NewDominosPaymentError("A tip can only be paid with a credit card")
+	}
+
+	// Charge capped payments first, in order, then the remainder
+	remaining := o.Total().Amount
+	for _, payment := range o.Payments {
+		if payment == remainder {
+			continue
+		}
+		amount := payment.MaxAmount.Amount
+		if amount < 0 {
+			amount = 0
+		}
+		if amount > remaining {
+			amount = remaining
+		}
+		payment.Amount.Amount = amount
+		remaining -= amount
+	}
+	if remainder != nil {
+		remainder.Amount.Amount = remaining
+		remaining = 0
+	}
+	if remaining > 0 {
+		return utils.NewDominosPaymentError("Payments don't cover the order total of " + o.Total().String())
+	}
+
+	for _, payment := range o.Payments {
+		payment.TipAmount.Amount = 0
+	}
+	if tipped != nil {
+		tipped.TipAmount.Amount = o.Tip.Amount
+	}
+
+	return nil
+}
+
+// CheckPaymentTotals checks that a priced order's payments add up to its total,
+// that no payment exceeds its MaxAmount, and that the tips add up to Tip if set
+func (o *Order) CheckPaymentTotals() error {
+	if !o.isPriced() {
+		return nil
+	}
+
+	paid := Money{Currency: o.Currency}
+	tips := Money{Currency: o.Currency}
+	for _, payment := range o.Payments {
+		if payment.MaxAmount != nil {
+			if over, err := payment.Amount.Cmp(*payment.MaxAmount); err != nil {
+				return err
+			} else if over > 0 {
+				return utils.NewDominosPaymentError("A " + payment.Type + " payment of " + payment.Amount.String() + " exceeds its maximum of " + payment.MaxAmount.String())
+			}
+		}
+
+		var err error
+		if paid, err = paid.Add(payment.Amount); err != nil {
+			return err
+		}
+		if tips, err = tips.Add(payment.TipAmount); err != nil {
+			return err
+		}
+	}
+
+	if cmp, err := paid.Cmp(o.Total()); err != nil {
+		return err
+	} else if cmp != 0 {
+		return utils.NewDominosPaymentError("Payments total " + paid.String() + " but the order total is " + o.Total().String())
+	}
+
+	if !o.Tip.IsZero() {
+		if cmp, err := tips.Cmp(o.Tip); err != nil {
+			return err
+		} else if cmp != 0 {
+			return utils.NewDominosPaymentError("Payment tips total " + tips.String() + " but the order tip is " + o.Tip.String())
+		}
+	}
+
+	return nil
+}
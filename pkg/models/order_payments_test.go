@@ -0,0 +1,153 @@
+package models
+
+import "testing"
+
+// pricedOrder returns an order as Price leaves it, without making requests
+func pricedOrder(customer int64, food int64) *Order {
+	return &Order{
+		Currency: "USD",
+		AmountsBreakdown: map[string]Money{
+			"Customer":        NewMoney(customer, "USD"),
+			"FoodAndBeverage": NewMoney(food, "USD"),
+		},
+	}
+}
+
+// addPayments adds payments to an order, failing the test if one is rejected
+func addPayments(t *testing.T, order *Order, payments ...*Payment) {
+	t.Helper()
+
+	for _, payment := range payments {
+		if err := order.AddPayment(payment); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAssignPaymentAmounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		balances []int64 // gift card balances, before one card paying the rest
+		tip      int64
+		want     []int64
+	}{
+		{"card only", nil, 0, []int64{2500}},
+		{"gift card first", []int64{1000}, 0, []int64{1000, 1500}},
+		{"empty gift card", []int64{0}, 0, []int64{0, 2500}},
+		{"gift card covers it", []int64{3000}, 0, []int64{2500, 0}},
+		{"two gift cards", []int64{2000, 2000}, 300, []int64{2000, 500, 0}},
+	}
+	for _, test := range tests {
+		order := pricedOrder(2500, 2000)
+		for _, balance := range test.balances {
+			addPayments(t, order, NewGiftCardPayment("6006491234567890", "1234", NewMoney(balance, "USD")))
+		}
+		card := NewTokenizedPayment("nonce", "10001", Money{})
+		addPayments(t, order, card)
+		if err := order.SetTip(NewMoney(test.tip, "USD")); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := order.AssignPaymentAmounts(); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if len(order.Payments) != len(test.want) {
+			t.Errorf("%s: %d payments, want %d", test.name, len(order.Payments), len(test.want))
+			continue
+		}
+		for i, payment := range order.Payments {
+			if payment.Amount != NewMoney(test.want[i], "USD") {
+				t.Errorf("%s: payment %d = %v, want %d", test.name, i, payment.Amount, test.want[i])
+			}
+		}
+		if card.TipAmount != NewMoney(test.tip, "USD") {
+			t.Errorf("%s: card tip = %v, want %d", test.name, card.TipAmount, test.tip)
+		}
+		if err := order.CheckPaymentTotals(); err != nil {
+			t.Errorf("%s: CheckPaymentTotals: %v", test.name, err)
+		}
+	}
+}
+
+func TestAssignPaymentAmountsErrors(t *testing.T) {
+	unpriced := &Order{Currency: "USD"}
+	addPayments(t, unpriced, NewCashPayment(Money{}))
+	if err := unpriced.AssignPaymentAmounts(); err == nil {
+		t.Error("assigning amounts before pricing succeeded")
+	}
+
+	twoUncapped := pricedOrder(2500, 2000)
+	addPayments(t, twoUncapped, NewCashPayment(Money{}), NewCashPayment(Money{}))
+	if err := twoUncapped.AssignPaymentAmounts(); err == nil {
+		t.Error("two payments paying the rest succeeded")
+	}
+
+	short := pricedOrder(2500, 2000)
+	addPayments(t, short, NewGiftCardPayment("6006491234567890", "1234", NewMoney(1000, "USD")))
+	if err := short.AssignPaymentAmounts(); err == nil {
+		t.Error("payments short of the total succeeded")
+	}
+
+	cashTip := pricedOrder(2500, 2000)
+	addPayments(t, cashTip, NewCashPayment(Money{}))
+	if err := cashTip.SetTip(NewMoney(300, "USD")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cashTip.AssignPaymentAmounts(); err == nil {
+		t.Error("a tip without a credit card succeeded")
+	}
+}
+
+func TestCheckPaymentTotals(t *testing.T) {
+	order := pricedOrder(2500, 2000)
+	gift := NewGiftCardPayment("6006491234567890", "1234", NewMoney(1000, "USD"))
+	addPayments(t, order, gift, NewCashPayment(NewMoney(1500, "USD")))
+	if err := order.CheckPaymentTotals(); err != nil {
+		t.Errorf("matching payments: %v", err)
+	}
+
+	gift.Amount = NewMoney(1200, "USD")
+	if err := order.CheckPaymentTotals(); err == nil {
+		t.Error("a payment over its MaxAmount passed")
+	}
+
+	gift.Amount = NewMoney(900, "USD")
+	if err := order.CheckPaymentTotals(); err == nil {
+		t.Error("payments short of the total passed")
+	}
+}
+
+func TestAddPaymentCurrency(t *testing.T) {
+	order := pricedOrder(2500, 2000)
+
+	giftCard := NewGiftCardPayment("6006491234567890", "1234", NewMoney(1000, "CAD"))
+	if err := order.AddPayment(giftCard); err == nil {
+		t.Error("a CAD gift card was added to a USD order")
+	}
+	if len(order.Payments) != 0 {
+		t.Errorf("rejected payment was kept: %v", order.Payments)
+	}
+	if giftCard.MaxAmount.Currency != "CAD" {
+		t.Errorf("rejected gift card balance = %v, want it left in CAD", giftCard.MaxAmount)
+	}
+
+	// Amounts without a currency take the order's
+	cash := &Payment{Type: PaymentTypeCash, Amount: Money{Amount: 2500}}
+	addPayments(t, order, cash)
+	if cash.Amount != NewMoney(2500, "USD") || cash.TipAmount.Currency != "USD" {
+		t.Errorf("cash = %v tip %v, want USD", cash.Amount, cash.TipAmount)
+	}
+
+	// Payments appended directly aren't relabelled either
+	order.Payments = append(order.Payments, giftCard)
+	if err := order.AssignPaymentAmounts(); err == nil {
+		t.Error("AssignPaymentAmounts accepted a CAD payment on a USD order")
+	}
+	if err := order.CheckPaymentTotals(); err == nil {
+		t.Error("CheckPaymentTotals accepted a CAD payment on a USD order")
+	}
+	if giftCard.MaxAmount.Currency != "CAD" {
+		t.Errorf("gift card balance = %v, want it left in CAD", giftCard.MaxAmount)
+	}
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// DefaultTipPercents are the percentages offered by TipOptions when none are given
+var DefaultTipPercents = []float64{15, 18, 20}
+
+// TipOption is a suggested driver tip
+type TipOption struct {
+	// Percent of the food total, or zero for a fixed amount
+	Percent float64
+	Amount  Money
+}
+
+// SetTipPercent tips a percentage of the food total, replacing any tip set
+// before. The tip is recomputed each time the order is priced, so it can be set
+// before Price; a percentage of zero removes the tip.
+func (o *Order) SetTipPercent(percent float64) error {
+	if percent < 0 || math.IsNaN(percent) || math.IsInf(percent, 0) {
+		return utils.NewDominosPaymentError("Invalid tip percentage " + strconv.FormatFloat(percent, 'f', -1, 64))
+	}
+
+	o.TipPercent = percent
+	o.Tip = Money{Currency: o.Currency}
+	o.applyTip()
+	return nil
+}
+
+// FoodTotal returns the priced food and beverage total that percentage tips are
+// based on, or zero if the order hasn't been priced
+func (o *Order) FoodTotal() Money {
+	if total, ok := o.AmountsBreakdown["FoodAndBeverage"]; ok {
+		return total
+	}
+	if total, ok := o.Amounts["FoodAndBeverage"]; ok {
+		return total
+	}
+	return Money{Currency: o.Currency}
+}
+
+// TotalWithTip returns the priced total plus the tip, what the customer is
+// charged in all
+func (o *Order) TotalWithTip() Money {
+	total, err := o.Total().Add(o.Tip)
+	if err != nil {
+		return o.Total()
+	}
+	return total
+}
+
+// TipOptions returns tips for each percentage of the priced food total, using
+// DefaultTipPercents if none are given
+func (o *Order) TipOptions(percents ...float64) ([]TipOption, error) {
+	if !o.isPriced() {
+		return nil, utils.NewDominosPaymentError("Order must be priced before calculating tips")
+	}
+	if len(percents) == 0 {
+		percents = DefaultTipPercents
+	}
+
+	options := make([]TipOption, len(percents))
+	for i, percent := range percents {
+		options[i] = TipOption{Percent: percent, Amount: percentOf(o.FoodTotal(), percent)}
+	}
+	return options, nil
+}
+
+// CheckTip checks that the tips are in the order's currency and that the store
+// takes tips for the order's service method and with the payment carrying each
+// tip. The store profile is fetched if one hasn't been set with SetStore.
+func (o *Order) CheckTip() error {
+	if err := o.checkCurrency(o.Tip, "A tip"); err != nil {
+		return err
+	}
+
+	tipped := make([]*Payment, 0, len(o.Payments))
+	for _, payment := range o.Payments {
+		if err := o.checkCurrency(payment.TipAmount, "A tip"); err != nil {
+			return err
+		}
+		if !payment.TipAmount.IsZero() {
+			tipped = append(tipped, payment)
+		}
+	}
+	if o.Tip.IsZero() && len(tipped) == 0 {
+		return nil
+	}
+
+	store, err := o.loadStore()
+	if err != nil {
+		return err
+	}
+
+	if !store.AcceptsTip(o.ServiceMethod) {
+		return utils.NewDominosPaymentError("Store " + o.StoreID + " does not accept tips on " + o.ServiceMethod + " orders")
+	}
+	for _, payment := range tipped {
+		if !store.AcceptsTipPaymentType(payment.Type) {
+			return utils.NewDominosPaymentError("Store " + o.StoreID + " does not accept tips on " + payment.Type + " payments")
+		}
+	}
+
+	return nil
+}
+
+// applyTip recomputes a percentage tip from the food total once the order is priced
+func (o *Order) applyTip() {
+	if o.TipPercent > 0 && o.isPriced() {
+		o.Tip = percentOf(o.FoodTotal(), o.TipPercent)
+	}
+}
+
+// percentOf returns percent of an amount, rounded half away from zero to the
+// currency's minor unit
+func percentOf(amount Money, percent float64) Money {
+	return Money{Amount: int64(math.Round(float64(amount.Amount) * percent / 100)), Currency: amount.Currency}
+}
@@ -0,0 +1,181 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSetTipPercent(t *testing.T) {
+	order := pricedOrder(2500, 2000)
+
+	if err := order.SetTipPercent(18); err != nil {
+		t.Fatal(err)
+	}
+	if order.Tip != NewMoney(360, "USD") {
+		t.Errorf("18%% tip = %v, want 3.60 USD", order.Tip)
+	}
+	if _, ok := order.AmountsBreakdown["Tip"]; ok {
+		t.Error("tip was added to AmountsBreakdown")
+	}
+	if got := order.TotalWithTip(); got != NewMoney(2860, "USD") {
+		t.Errorf("TotalWithTip() = %v, want 28.60 USD", got)
+	}
+
+	// Pricing again recomputes the tip from the new food total
+	order.AmountsBreakdown["FoodAndBeverage"] = NewMoney(1999, "USD")
+	order.applyTip()
+	if order.Tip != NewMoney(360, "USD") {
+		t.Errorf("18%% of 19.99 = %v, want 3.60 USD", order.Tip)
+	}
+
+	if err := order.SetTipPercent(0); err != nil {
+		t.Fatal(err)
+	}
+	if !order.Tip.IsZero() {
+		t.Errorf("tip after SetTipPercent(0) = %v, want zero", order.Tip)
+	}
+
+	if err := order.SetTip(NewMoney(500, "USD")); err != nil {
+		t.Fatal(err)
+	}
+	order.applyTip()
+	if order.Tip != NewMoney(500, "USD") || order.TipPercent != 0 {
+		t.Errorf("fixed tip = %v (%v%%), want 5.00 USD", order.Tip, order.TipPercent)
+	}
+
+	for _, percent := range []float64{-1, math.NaN(), math.Inf(1)} {
+		if err := order.SetTipPercent(percent); err == nil {
+			t.Errorf("SetTipPercent(%v) succeeded", percent)
+		}
+	}
+}
+
+func TestSetTipPercentBeforePrice(t *testing.T) {
+	order := &Order{Currency: "USD"}
+	if err := order.SetTip(NewMoney(300, "USD")); err != nil {
+		t.Fatal(err)
+	}
+	if err := order.SetTipPercent(20); err != nil {
+		t.Fatal(err)
+	}
+	if !order.Tip.IsZero() {
+		t.Errorf("unpriced percentage tip = %v, want zero until priced", order.Tip)
+	}
+
+	order.AmountsBreakdown = pricedOrder(2500, 2000).AmountsBreakdown
+	order.applyTip()
+	if order.Tip != NewMoney(400, "USD") {
+		t.Errorf("20%% tip once priced = %v, want 4.00 USD", order.Tip)
+	}
+}
+
+func TestTipOptions(t *testing.T) {
+	if _, err := (&Order{}).TipOptions(); err == nil {
+		t.Error("TipOptions before pricing succeeded")
+	}
+
+	options, err := pricedOrder(2500, 1999).TipOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int64{300, 360, 400}
+	for i, option := range options {
+		if option.Percent != DefaultTipPercents[i] || option.Amount != NewMoney(want[i], "USD") {
+			t.Errorf("option %d = %+v, want %d", i, option, want[i])
+		}
+	}
+}
+
+func TestStoreAcceptsTip(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		atCheckout *bool
+		carryout   *bool
+		method     string
+		want       bool
+	}{
+		{nil, nil, "Delivery", true},
+		{nil, nil, "Carryout", true},
+		{&yes, nil, "Delivery", true},
+		{&no, nil, "Delivery", false},
+		{&no, &yes, "Carryout", false},
+		{&yes, &no, "Carryout", false},
+		{&yes, &no, "Delivery", true},
+		{&yes, &yes, "carryout", true},
+	}
+	for _, test := range tests {
+		store := &Store{IsTippingAllowedAtCheckout: test.atCheckout, AllowCarryoutTips: test.carryout}
+		if got := store.AcceptsTip(test.method); got != test.want {
+			t.Errorf("AcceptsTip(%s) with %v/%v = %v, want %v", test.method, test.atCheckout, test.carryout, got, test.want)
+		}
+	}
+}
+
+func TestCheckTip(t *testing.T) {
+	no := false
+	order := pricedOrder(2500, 2000)
+	order.ServiceMethod = "Delivery"
+	order.SetStore(&Store{StoreID: "1", AcceptableTipPaymentTypes: []string{PaymentTypeCreditCard}})
+
+	addPayments(t, order, NewTokenizedPayment("nonce", "10001", Money{}))
+	if err := order.SetTip(NewMoney(300, "USD")); err != nil {
+		t.Fatal(err)
+	}
+	if err := order.AssignPaymentAmounts(); err != nil {
+		t.Fatal(err)
+	}
+	if err := order.CheckTip(); err != nil {
+		t.Errorf("CheckTip() = %v", err)
+	}
+
+	order.store.IsTippingAllowedAtCheckout = &no
+	if err := order.CheckTip(); err == nil {
+		t.Error("CheckTip() passed at a store that doesn't take tips")
+	}
+
+	order.store.IsTippingAllowedAtCheckout = nil
+	order.store.AcceptableTipPaymentTypes = []string{PaymentTypeCash}
+	if err := order.CheckTip(); err == nil {
+		t.Error("CheckTip() passed with a tip on a payment type the store refuses")
+	}
+}
+
+func TestSetTipCurrency(t *testing.T) {
+	order := pricedOrder(2500, 2000)
+	card := NewTokenizedPayment("nonce", "10001", Money{})
+	addPayments(t, order, card)
+
+	if err := order.SetTip(NewMoney(500, "JPY")); err == nil {
+		t.Error("a JPY tip was set on a USD order")
+	}
+	if !order.Tip.IsZero() {
+		t.Errorf("rejected tip was kept: %v", order.Tip)
+	}
+
+	// A tip without a currency takes the order's
+	if err := order.SetTip(Money{Amount: 300}); err != nil {
+		t.Fatal(err)
+	}
+	if order.Tip != NewMoney(300, "USD") {
+		t.Errorf("tip = %v, want 3.00 USD", order.Tip)
+	}
+
+	// Tips set directly aren't relabelled, and are caught before placing
+	order.SetStore(&Store{StoreID: "1"})
+	order.Tip = NewMoney(500, "JPY")
+	if err := order.AssignPaymentAmounts(); err == nil {
+		t.Error("AssignPaymentAmounts accepted a JPY tip on a USD order")
+	}
+	if err := order.CheckTip(); err == nil {
+		t.Error("CheckTip accepted a JPY tip on a USD order")
+	}
+	if order.Tip != NewMoney(500, "JPY") {
+		t.Errorf("tip = %v, want it left at 500 JPY", order.Tip)
+	}
+
+	order.Tip = Money{Currency: "USD"}
+	card.TipAmount = NewMoney(500, "JPY")
+	if err := order.CheckTip(); err == nil {
+		t.Error("CheckTip accepted a JPY tip on a payment")
+	}
+}
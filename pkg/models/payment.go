@@ -2,24 +2,57 @@ package models
 
 import (
 	"strings"
+	"time"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// Payment types accepted by Domino's
+const (
+	PaymentTypeCreditCard = "CreditCard"
+	PaymentTypeCash       = "Cash"
+	PaymentTypeGiftCard   = "GiftCard"
+	PaymentTypeDoorCredit = "DoorCredit"
 )
 
+// Fields sent with each payment type, besides Type, Amount and TipAmount
+var paymentTypeFields = map[string][]string{
+	PaymentTypeCreditCard: {"Number", "CardType", "Expiration", "SecurityCode", "PostalCode"},
+	PaymentTypeGiftCard:   {"Number", "SecurityCode"},
+	PaymentTypeCash:       {},
+	PaymentTypeDoorCredit: {},
+}
+
+// Fields sent with a saved card, which is a CreditCard referenced by CardID
+var savedCardFields = []string{"CardID", "SecurityCode", "PostalCode"}
+
 // Payment represents a payment method for an order
 type Payment struct {
 	DominosFormat
-	Type         string  `json:"type"`
-	Amount       float64 `json:"amount"`
-	Number       string  `json:"number"`
-	Expiration   string  `json:"expiration"` // MM/YY format
-	SecurityCode string  `json:"securityCode"`
-	PostalCode   string  `json:"postalCode"`
-	TipAmount    float64 `json:"tipAmount"`
+	Type         string `json:"type"`
+	Amount       Money  `json:"amount"`
+	Number       string `json:"number"`
+	Expiration   string `json:"expiration"`   // MM/YY format
+	SecurityCode string `json:"securityCode"` // Card CVV or gift card PIN
+	PostalCode   string `json:"postalCode"`
+	TipAmount    Money  `json:"tipAmount"`
+	CardID       string `json:"cardID"` // Token of a card saved to a Domino's profile
+	CardType     string `json:"cardType"`
+	// PaymentMethodNonce is a Braintree nonce for a card tokenized by the front end
+	PaymentMethodNonce string `json:"paymentMethodNonce"`
+	// MaxAmount caps what Order.AssignPaymentAmounts charges to this payment,
+	// such as a gift card's balance. Nil means it pays the remaining balance.
+	MaxAmount *Money `json:"-"`
 }
 
 // NewPayment creates a new payment from payment data
 func NewPayment(paymentData map[string]interface{}) (*Payment, error) {
+	// Amounts are in the current market's currency, which they are parsed in
+	currency := utils.CurrentMarket().Currency
 	payment := &Payment{
-		Type: "CreditCard", // Default payment type
+		Type:      PaymentTypeCreditCard, // Default payment type
+		Amount:    Money{Currency: currency},
+		TipAmount: Money{Currency: currency},
 	}
 
 	// Set payment fields from paymentData
@@ -32,5 +65,126 @@ func NewPayment(paymentData map[string]interface{}) (*Payment, error) {
 	// Sanitize expiration date (remove slashes)
 	payment.Expiration = strings.ReplaceAll(payment.Expiration, "/", "")
 
+	// Detect the card brand; ValidateCard reports unrecognized cards
+	if payment.Type == PaymentTypeCreditCard && payment.CardType == "" {
+		if brand := DetectCardBrand(payment.Number); brand != nil {
+			payment.CardType = brand.CardType
+		}
+	}
+
 	return payment, nil
 }
+
+// NewCashPayment creates a payment made in cash at the door or counter
+func NewCashPayment(amount Money) *Payment {
+	return newTypedPayment(PaymentTypeCash, amount)
+}
+
+// NewDoorCreditPayment creates a payment made by card to the driver at the door
+func NewDoorCreditPayment(amount Money) *Payment {
+	return newTypedPayment(PaymentTypeDoorCredit, amount)
+}
+
+// NewGiftCardPayment creates a payment from a Domino's gift card number and PIN.
+// The balance caps the amount charged to the card when splitting an order's total.
+func NewGiftCardPayment(number string, pin string, balance Money) *Payment {
+	payment := newTypedPayment(PaymentTypeGiftCard, balance)
+	maxAmount := payment.Amount
+	payment.MaxAmount = &maxAmount
+	payment.Number = strings.NewReplacer("-", "", " ", "").Replace(number)
+	payment.SecurityCode = pin
+	return payment
+}
+
+// NewSavedCardPayment creates a payment from a card saved to a Domino's profile
+func NewSavedCardPayment(cardID string, amount Money) *Payment {
+	payment := newTypedPayment(PaymentTypeCreditCard, amount)
+	payment.CardID = cardID
+	return payment
+}
+
+// newTypedPayment creates a payment of paymentType in the amount's currency
+func newTypedPayment(paymentType string, amount Money) *Payment {
+	if amount.Currency == "" {
+		amount.Currency = utils.CurrentMarket().Currency
+	}
+
+	return &Payment{
+		Type:      paymentType,
+		Amount:    amount,
+		TipAmount: Money{Currency: amount.Currency},
+	}
+}
+
+// IsSavedCard reports whether the payment references a saved card instead of a card number
+func (p *Payment) IsSavedCard() bool {
+	return p.Type == PaymentTypeCreditCard && p.CardID != ""
+}
+
+// Validate checks that the payment has the fields its type requires. Credit
+// cards are also checked with ValidateCard.
+func (p *Payment) Validate() error {
+	if p.Amount.Amount < 0 || p.TipAmount.Amount < 0 {
+		return utils.NewDominosPaymentError("Payment amounts cannot be negative")
+	}
+
+	switch p.Type {
+	case PaymentTypeCreditCard:
+		if p.IsSavedCard() || p.IsTokenized() {
+			return nil
+		}
+		if p.Number == "" || p.Expiration == "" || p.SecurityCode == "" {
+			return utils.NewDominosPaymentError("Credit card payments need a number, expiration and security code")
+		}
+		if p.PostalCode == "" {
+			return utils.NewDominosPaymentError("Credit card payments need a billing postal code")
+		}
+		if err := p.ValidateCard(time.Now()); err != nil {
+			return err
+		}
+	case PaymentTypeGiftCard:
+		if p.Number == "" || p.SecurityCode == "" {
+			return utils.NewDominosPaymentError("Gift card payments need a card number and PIN")
+		}
+	case PaymentTypeCash, PaymentTypeDoorCredit:
+	default:
+		return utils.NewDominosPaymentError("Unknown payment type " + p.Type)
+	}
+
+	return nil
+}
+
+// GetFormatted returns the payment as a map with PascalCase keys, containing
+// only the fields that belong to its payment type
+func (p *Payment) GetFormatted() map[string]interface{} {
+	formatted := getFormatted(p)
+
+	fields, ok := paymentTypeFields[p.Type]
+	switch {
+	case p.IsSavedCard():
+		fields, ok = savedCardFields, true
+	case p.IsTokenized():
+		fields, ok = tokenizedCardFields, true
+	}
+	if !ok {
+		return formatted
+	}
+
+	result := map[string]interface{}{
+		"Type":      formatted["Type"],
+		"Amount":    formatted["Amount"],
+		"TipAmount": formatted["TipAmount"],
+	}
+	for _, field := range fields {
+		if value, ok := formatted[field]; ok && value != "" {
+			result[field] = value
+		}
+	}
+
+	return result
+}
+
+// SetFormatted updates the payment from a map with keys in any format
+func (p *Payment) SetFormatted(data map[string]interface{}) {
+	setFormatted(p, data)
+}
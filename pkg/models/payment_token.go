@@ -0,0 +1,44 @@
+package models
+
+import (
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// Fields sent with a tokenized card, which is a CreditCard referenced by a Braintree nonce
+var tokenizedCardFields = []string{"PaymentMethodNonce", "PostalCode"}
+
+// GetPaymentClientToken requests a Braintree client token from the payment gateway.
+// The front end uses it to tokenize the card and sends back a payment method nonce
+// for NewTokenizedPayment, so card numbers never pass through the caller's backend.
+func GetPaymentClientToken() (string, error) {
+	if utils.URLs.Token == "" {
+		return "", utils.NewDominosPaymentError("The current market has no payment token endpoint")
+	}
+
+	response, err := utils.Get(utils.URLs.Token)
+	if err != nil {
+		return "", err
+	}
+
+	for _, key := range []string{"clientToken", "ClientToken", "token", "Token"} {
+		if token, ok := response[key].(string); ok && token != "" {
+			return token, nil
+		}
+	}
+
+	return "", utils.NewDominosPaymentError(response)
+}
+
+// NewTokenizedPayment creates a credit card payment from a Braintree payment
+// method nonce obtained by the front end
+func NewTokenizedPayment(nonce string, postalCode string, amount Money) *Payment {
+	payment := newTypedPayment(PaymentTypeCreditCard, amount)
+	payment.PaymentMethodNonce = nonce
+	payment.PostalCode = postalCode
+	return payment
+}
+
+// IsTokenized reports whether the payment carries a Braintree nonce instead of a card number
+func (p *Payment) IsTokenized() bool {
+	return p.Type == PaymentTypeCreditCard && p.PaymentMethodNonce != ""
+}
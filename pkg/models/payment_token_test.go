@@ -0,0 +1,64 @@
+package models
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+func TestGetPaymentClientToken(t *testing.T) {
+	tests := []struct {
+		response map[string]interface{}
+		want     string
+	}{
+		{map[string]interface{}{"clientToken": "token-1"}, "token-1"},
+		{map[string]interface{}{"ClientToken": "token-2"}, "token-2"},
+		{map[string]interface{}{"Status": -1, "StatusItems": []interface{}{}}, ""},
+		{map[string]interface{}{"clientToken": ""}, ""},
+	}
+	for _, test := range tests {
+		var method string
+		server := jsonServer(t, func(r *http.Request) interface{} {
+			method = r.Method
+			return test.response
+		})
+		useURLs(t, func(urls *utils.URLConfig) { urls.Token = server.URL + "/token" })
+
+		token, err := GetPaymentClientToken()
+		if method != http.MethodGet {
+			t.Errorf("token requested with %s, want GET", method)
+		}
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("GetPaymentClientToken() with %v = %q, want an error", test.response, token)
+			}
+			continue
+		}
+		if err != nil || token != test.want {
+			t.Errorf("GetPaymentClientToken() = %q, %v, want %q", token, err, test.want)
+		}
+	}
+}
+
+func TestGetPaymentClientTokenNoEndpoint(t *testing.T) {
+	useURLs(t, func(urls *utils.URLConfig) { urls.Token = "" })
+
+	if _, err := GetPaymentClientToken(); err == nil {
+		t.Error("GetPaymentClientToken() without an endpoint succeeded")
+	}
+}
+
+func TestNewTokenizedPayment(t *testing.T) {
+	payment := NewTokenizedPayment("nonce", "10001", NewMoney(1999, "USD"))
+
+	if !payment.IsTokenized() || payment.Type != PaymentTypeCreditCard {
+		t.Errorf("payment = %+v, want a tokenized credit card", payment)
+	}
+	if payment.Number != "" || payment.Amount != NewMoney(1999, "USD") || payment.PostalCode != "10001" {
+		t.Errorf("payment = %+v", payment)
+	}
+	if (&Payment{Type: PaymentTypeCash, PaymentMethodNonce: "nonce"}).IsTokenized() {
+		t.Error("a cash payment counts as tokenized")
+	}
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// Product represents a product on a store's menu, such as a pizza or a side,
+// which is ordered as one of its variants
+type Product struct {
+	DominosFormat
+	Code            string                 `json:"code"`
+	Name            string                 `json:"name"`
+	Description     string                 `json:"description"`
+	ProductType     string                 `json:"productType"`
+	ImageCode       string                 `json:"imageCode"`
+	Variants        []string               `json:"variants"`
+	DefaultToppings string                 `json:"defaultToppings"`
+	DefaultSides    string                 `json:"defaultSides"`
+	Tags            map[string]interface{} `json:"tags"`
+}
+
+// NewProduct creates a product from its menu data
+func NewProduct(productData map[string]interface{}) *Product {
+	product := &Product{}
+	product.SetFormatted(productData)
+	product.SetDominosAPIResponse(productData)
+	return product
+}
+
+// LookupProduct returns a product from the menu
+func (m *Menu) LookupProduct(productCode string) (*Product, bool) {
+	data, ok := m.GetProduct(productCode)
+	if !ok {
+		return nil, false
+	}
+
+	product := NewProduct(data)
+	if product.Code == "" {
+		product.Code = productCode
+	}
+	return product, true
+}
+
+// AllProducts returns every product on the menu, sorted by code
+func (m *Menu) AllProducts() []*Product {
+	codes := make([]string, 0, len(m.Products))
+	for code := range m.Products {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	products := make([]*Product, 0, len(codes))
+	for _, code := range codes {
+		if product, ok := m.LookupProduct(code); ok {
+			products = append(products, product)
+		}
+	}
+	return products
+}
+
+// ImageURL returns the URL of the product's image in the current market. US
+// templates name images by ${productCode}, Canada's by ${itemCode}, the product's
+// image code.
+func (p *Product) ImageURL() string {
+	if utils.URLs.Images == "" {
+		return ""
+	}
+
+	imageCode := p.ImageCode
+	if imageCode == "" {
+		imageCode = p.Code
+	}
+
+	return strings.NewReplacer(
+		"${productCode}", p.Code,
+		"${itemCode}", imageCode,
+	).Replace(utils.URLs.Images)
+}
+
+// GetFormatted returns the product as a map with PascalCase keys
+func (p *Product) GetFormatted() map[string]interface{} {
+	return getFormatted(p)
+}
+
+// SetFormatted updates the product from a map with keys in any format.
+// Nested keys such as tag names are left untouched.
+func (p *Product) SetFormatted(data map[string]interface{}) {
+	setFormattedShallow(p, data)
+}
@@ -0,0 +1,270 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // decode GIF images as well as JPEG and PNG
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// Size of placeholders when the fetcher doesn't resize
+const (
+	DefaultPlaceholderWidth  = 400
+	DefaultPlaceholderHeight = 400
+)
+
+// ProductImage is a product image saved by an ImageFetcher
+type ProductImage struct {
+	Path string
+	// Placeholder is set when the product has no image and a placeholder was used
+	Placeholder bool
+}
+
+// ImageFetcher downloads product images into a cache directory, optionally resized
+type ImageFetcher struct {
+	CacheDir string
+	// Width and Height resize images; if one is zero the aspect ratio is kept,
+	// and if both are the image is saved as is
+	Width  int
+	Height int
+	// Placeholder is used for products without an image; a gray JPEG if nil
+	Placeholder image.Image
+	Client      *http.Client
+}
+
+// NewImageFetcher creates an image fetcher that caches images in cacheDir
+func NewImageFetcher(cacheDir string) *ImageFetcher {
+	return &ImageFetcher{
+		CacheDir: cacheDir,
+		Client:   utils.Client,
+	}
+}
+
+// Fetch returns the cached image of a product, downloading and resizing it the
+// first time. Products whose image is missing get a placeholder, which isn't cached
+// in case the image shows up later.
+func (f *ImageFetcher) Fetch(ctx context.Context, product *Product) (*ProductImage, error) {
+	if err := os.MkdirAll(f.CacheDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	name := f.fileName(product.Code)
+	for _, ext := range imageExtensions {
+		path := filepath.Join(f.CacheDir, name+ext)
+		if _, err := os.Stat(path); err == nil {
+			return &ProductImage{Path: path}, nil
+		}
+	}
+
+	data, found, err := f.download(ctx, product.ImageURL())
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return f.placeholder()
+	}
+
+	ext, ok := imageExtension(data)
+	if !ok {
+		return f.placeholder()
+	}
+	if f.Width > 0 || f.Height > 0 {
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return f.placeholder()
+		}
+		if data, err = encodeJPEG(resizeImage(img, f.Width, f.Height)); err != nil {
+			return nil, err
+		}
+		ext = ".jpg"
+	}
+
+	path := filepath.Join(f.CacheDir, name+ext)
+	if err := writeFileAtomic(path, data); err != nil {
+		return nil, err
+	}
+	return &ProductImage{Path: path}, nil
+}
+
+// download fetches an image, reporting found as false if there is none
+func (f *ImageFetcher) download(ctx context.Context, url string) ([]byte, bool, error) {
+	if url == "" {
+		return nil, false, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	client := f.Client
+	if client == nil {
+		client = utils.Client
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+		return nil, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, utils.NewDominosProductsError("Image request failed with status " + resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	if !strings.HasPrefix(http.DetectContentType(data), "image/") {
+		return nil, false, nil
+	}
+	return data, true, nil
+}
+
+// placeholder writes the placeholder image for the fetcher's size
+func (f *ImageFetcher) placeholder() (*ProductImage, error) {
+	width, height := f.Width, f.Height
+	if width == 0 && height == 0 {
+		width, height = DefaultPlaceholderWidth, DefaultPlaceholderHeight
+	}
+
+	img := f.Placeholder
+	if img == nil {
+		if width == 0 {
+			width = height
+		}
+		if height == 0 {
+			height = width
+		}
+		gray := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(gray, gray.Bounds(), &image.Uniform{C: color.RGBA{R: 0xdd, G: 0xdd, B: 0xdd, A: 0xff}}, image.Point{}, draw.Src)
+		img = gray
+	} else {
+		img = resizeImage(img, width, height)
+	}
+
+	data, err := encodeJPEG(img)
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(f.CacheDir, f.fileName("_placeholder")+".jpg")
+	if err := writeFileAtomic(path, data); err != nil {
+		return nil, err
+	}
+	return &ProductImage{Path: path, Placeholder: true}, nil
+}
+
+// fileName names a cached image after the market, its code and size, without
+// an extension. Markets share product codes but not images.
+func (f *ImageFetcher) fileName(code string) string {
+	name := strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(utils.URLs.Market + "_" + code)
+	if f.Width > 0 || f.Height > 0 {
+		name += "_" + strconv.Itoa(f.Width) + "x" + strconv.Itoa(f.Height)
+	}
+	return name
+}
+
+// Extensions of cached images, by their sniffed content type. Only formats
+// with a registered decoder are listed, so every cached image can be resized.
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// imageExtension returns the extension for an image's content, reporting ok as
+// false if it isn't a format the fetcher can decode
+func imageExtension(data []byte) (ext string, ok bool) {
+	ext, ok = imageExtensions[http.DetectContentType(data)]
+	return ext, ok
+}
+
+// resizeImage scales an image by averaging the source pixels under each target
+// pixel. A zero width or height keeps the aspect ratio.
+func resizeImage(src image.Image, width int, height int) image.Image {
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	if srcWidth == 0 || srcHeight == 0 {
+		return src
+	}
+
+	switch {
+	case width == 0 && height == 0:
+		return src
+	case width == 0:
+		width = max(1, srcWidth*height/srcHeight)
+	case height == 0:
+		height = max(1, srcHeight*width/srcWidth)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*srcHeight/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcHeight/height)
+
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*srcWidth/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcWidth/width)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// encodeJPEG encodes an image as a JPEG
+func encodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeFileAtomic writes a file through a temporary file so readers never see a
+// partial file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
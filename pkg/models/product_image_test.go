@@ -0,0 +1,120 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+func TestImageFetcherKeepsExtensionPerMarket(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	useURLs(t, func(urls *utils.URLConfig) {
+		urls.Images = server.URL + "/${productCode}.png"
+	})
+
+	fetcher := NewImageFetcher(t.TempDir())
+	product := &Product{Code: "S_PIZZA"}
+
+	saved, err := fetcher.Fetch(context.Background(), product)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(saved.Path) != "UNITED_STATES_S_PIZZA.png" {
+		t.Errorf("saved %q, want UNITED_STATES_S_PIZZA.png", filepath.Base(saved.Path))
+	}
+
+	if _, err := fetcher.Fetch(context.Background(), product); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("made %d requests, want the second fetch to be cached", requests)
+	}
+
+	utils.URLs.Market = "CANADA"
+	saved, err = fetcher.Fetch(context.Background(), product)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(saved.Path) != "CANADA_S_PIZZA.png" || requests != 2 {
+		t.Errorf("saved %q after %d requests, want a separate Canadian image", filepath.Base(saved.Path), requests)
+	}
+
+	fetcher.Width = 2
+	saved, err = fetcher.Fetch(context.Background(), product)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Ext(saved.Path) != ".jpg" {
+		t.Errorf("resized image saved as %q, want a JPEG", saved.Path)
+	}
+}
+
+func TestImageFetcherGIF(t *testing.T) {
+	var buf bytes.Buffer
+	palette := image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{color.Black, color.White})
+	if err := gif.Encode(&buf, palette, nil); err != nil {
+		t.Fatal(err)
+	}
+	// A WebP header, which the fetcher has no decoder for
+	webp := []byte("RIFF\x1a\x00\x00\x00WEBPVP8L\x0d\x00\x00\x00")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/S_WEBP.png" {
+			w.Write(webp)
+			return
+		}
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	useURLs(t, func(urls *utils.URLConfig) {
+		urls.Images = server.URL + "/${productCode}.png"
+	})
+
+	fetcher := NewImageFetcher(t.TempDir())
+	saved, err := fetcher.Fetch(context.Background(), &Product{Code: "S_PIZZA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Ext(saved.Path) != ".gif" || saved.Placeholder {
+		t.Errorf("saved %+v, want the GIF", saved)
+	}
+
+	fetcher.Width = 2
+	saved, err = fetcher.Fetch(context.Background(), &Product{Code: "S_PIZZA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Ext(saved.Path) != ".jpg" || saved.Placeholder {
+		t.Errorf("resized GIF saved as %+v, want a JPEG", saved)
+	}
+
+	fetcher.Width = 0
+	saved, err = fetcher.Fetch(context.Background(), &Product{Code: "S_WEBP"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved.Placeholder {
+		t.Errorf("saved %+v for a WebP image, want a placeholder", saved)
+	}
+}
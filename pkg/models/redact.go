@@ -0,0 +1,133 @@
+package models
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// Method-less copies of the models, used to print them without recursing
+// into their own Format methods
+type (
+	paymentFields  Payment
+	customerFields Customer
+	orderFields    Order
+)
+
+// formatModel formats a model for fmt, honouring the verb and its flags
+func formatModel(f fmt.State, verb rune, model interface{}) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), model)
+}
+
+// redacted returns a copy of the payment with card data masked, unless
+// utils.ShowSensitiveData is on
+func (p Payment) redacted() Payment {
+	p.DominosFormat = DominosFormat{}
+	if utils.SensitiveDataShown() {
+		return p
+	}
+
+	p.Number = utils.RedactCardNumber(p.Number)
+	p.Expiration = utils.RedactSecret(p.Expiration)
+	p.SecurityCode = utils.RedactSecret(p.SecurityCode)
+	p.CardID = utils.RedactSecret(p.CardID)
+	p.PaymentMethodNonce = utils.RedactSecret(p.PaymentMethodNonce)
+	return p
+}
+
+// Format prints the payment with card data redacted
+func (p Payment) Format(f fmt.State, verb rune) {
+	formatModel(f, verb, paymentFields(p.redacted()))
+}
+
+// String returns the payment with card data redacted
+func (p Payment) String() string {
+	return fmt.Sprintf("%+v", p)
+}
+
+// LogValue logs the payment fields Domino's receives, with card data redacted
+func (p Payment) LogValue() slog.Value {
+	redacted := p.redacted()
+	return slog.AnyValue(redacted.GetFormatted())
+}
+
+// redacted returns a copy of the customer with contact details masked, unless
+// utils.ShowSensitiveData is on
+func (c Customer) redacted() Customer {
+	c.DominosFormat = DominosFormat{}
+	if utils.SensitiveDataShown() {
+		return c
+	}
+
+	c.Phone = utils.RedactPhone(c.Phone)
+	c.Email = utils.RedactEmail(c.Email)
+	return c
+}
+
+// Format prints the customer with contact details redacted
+func (c Customer) Format(f fmt.State, verb rune) {
+	formatModel(f, verb, customerFields(c.redacted()))
+}
+
+// String returns the customer with contact details redacted
+func (c Customer) String() string {
+	return fmt.Sprintf("%+v", c)
+}
+
+// LogValue logs the customer with contact details redacted
+func (c Customer) LogValue() slog.Value {
+	redacted := c.redacted()
+	return slog.AnyValue(redacted.GetFormatted())
+}
+
+// redacted returns a copy of the order with contact details, payments and API
+// responses masked, unless utils.ShowSensitiveData is on
+func (o Order) redacted() Order {
+	o.DominosFormat = DominosFormat{}
+	if utils.SensitiveDataShown() {
+		return o
+	}
+
+	o.Phone = utils.RedactPhone(o.Phone)
+	o.Email = utils.RedactEmail(o.Email)
+
+	payments := make([]*Payment, len(o.Payments))
+	for i, payment := range o.Payments {
+		redacted := payment.redacted()
+		payments[i] = &redacted
+	}
+	o.Payments = payments
+
+	// Responses echo the order back, so they are only kept in redacted form
+	o.validationResponse, _ = utils.RedactValue(o.validationResponse).(map[string]interface{})
+	o.priceResponse, _ = utils.RedactValue(o.priceResponse).(map[string]interface{})
+	o.placeResponse, _ = utils.RedactValue(o.placeResponse).(map[string]interface{})
+	o.store = nil
+
+	return o
+}
+
+// Format prints the order with contact details and payments redacted
+func (o Order) Format(f fmt.State, verb rune) {
+	formatModel(f, verb, orderFields(o.redacted()))
+}
+
+// String returns the order with contact details and payments redacted
+func (o Order) String() string {
+	return fmt.Sprintf("%+v", o)
+}
+
+// LogValue logs the order with contact details and payments redacted
+func (o Order) LogValue() slog.Value {
+	redacted := o.redacted()
+	formatted := redacted.GetFormatted()
+
+	payments := make([]interface{}, len(redacted.Payments))
+	for i, payment := range redacted.Payments {
+		payments[i] = payment.GetFormatted()
+	}
+	formatted["Payments"] = payments
+
+	return slog.AnyValue(formatted)
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// Values that must never be printed or logged
+var sensitiveValues = []*regexp.Regexp{
+	regexp.MustCompile(`4111111111111111`),
+	regexp.MustCompile(`\b7391\b`),
+	regexp.MustCompile(`12/30`),
+	regexp.MustCompile(`fake-nonce`),
+	regexp.MustCompile(`5555551234`),
+	regexp.MustCompile(`jane@example\.com`),
+}
+
+// redactionOutputs prints and logs a model every way a caller might
+func redactionOutputs(model interface{ String() string }) map[string]string {
+	outputs := map[string]string{
+		"%v":     fmt.Sprintf("%v", model),
+		"%+v":    fmt.Sprintf("%+v", model),
+		"%#v":    fmt.Sprintf("%#v", model),
+		"%s":     fmt.Sprintf("%s", model),
+		"String": model.String(),
+	}
+
+	var jsonLog, textLog bytes.Buffer
+	slog.New(slog.NewJSONHandler(&jsonLog, nil)).Info("model", "value", model)
+	slog.New(slog.NewTextHandler(&textLog, nil)).Info("model", "value", model)
+	outputs["slog json"] = jsonLog.String()
+	outputs["slog text"] = textLog.String()
+
+	return outputs
+}
+
+// redactionFixtures returns a payment, a customer and an order holding both
+func redactionFixtures() (*Payment, *Customer, *Order) {
+	payment := &Payment{
+		Type:         PaymentTypeCreditCard,
+		Number:       "4111111111111111",
+		Expiration:   "12/30",
+		SecurityCode: "7391",
+		PostalCode:   "48104",
+	}
+	tokenized := &Payment{Type: PaymentTypeCreditCard, PaymentMethodNonce: "fake-nonce"}
+	customer := &Customer{FirstName: "Jane", Phone: "5555551234", Email: "jane@example.com"}
+	order := &Order{
+		FirstName: "Jane",
+		Phone:     "5555551234",
+		Email:     "jane@example.com",
+		Payments:  []*Payment{payment, tokenized},
+		priceResponse: map[string]interface{}{
+			"Order": map[string]interface{}{"Email": "jane@example.com", "Phone": "5555551234"},
+		},
+	}
+	return payment, customer, order
+}
+
+func TestRedaction(t *testing.T) {
+	payment, customer, order := redactionFixtures()
+	models := map[string]interface{ String() string }{
+		"Payment":  payment,
+		"Customer": customer,
+		"Order":    order,
+	}
+
+	for name, model := range models {
+		for output, text := range redactionOutputs(model) {
+			for _, sensitive := range sensitiveValues {
+				if sensitive.MatchString(text) {
+					t.Errorf("%s %s leaks %s: %s", name, output, sensitive, text)
+				}
+			}
+		}
+	}
+
+	// Payments nested in an order are logged, redacted
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("order", "order", order)
+	if !strings.Contains(buf.String(), "************1111") || !strings.Contains(buf.String(), "48104") {
+		t.Errorf("order log = %s, want its payment with the card's last four digits", buf.String())
+	}
+
+	if payment.Number != "4111111111111111" || order.Phone != "5555551234" {
+		t.Error("printing a model redacted the model itself")
+	}
+}
+
+func TestShowSensitiveData(t *testing.T) {
+	utils.ShowSensitiveData(true)
+	t.Cleanup(func() { utils.ShowSensitiveData(false) })
+
+	payment, customer, order := redactionFixtures()
+
+	if text := fmt.Sprintf("%+v", payment); !strings.Contains(text, "4111111111111111") || !strings.Contains(text, "7391") {
+		t.Errorf("payment = %s, want its card data", text)
+	}
+	if text := customer.String(); !strings.Contains(text, "jane@example.com") || !strings.Contains(text, "5555551234") {
+		t.Errorf("customer = %s, want its contact details", text)
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("order", "order", order)
+	if !strings.Contains(buf.String(), "4111111111111111") || !strings.Contains(buf.String(), "jane@example.com") {
+		t.Errorf("order log = %s, want its payment and contact details", buf.String())
+	}
+}
@@ -1,8 +1,8 @@
 package models
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
 	"strings"
@@ -30,12 +30,23 @@ type Store struct {
 		StoreLatitude  string `json:"storeLatitude"`
 		StoreLongitude string `json:"storeLongitude"`
 	} `json:"storeCoordinates"`
+	AcceptablePaymentTypes []string `json:"acceptablePaymentTypes"`
+	AcceptableCreditCards  []string `json:"acceptableCreditCards"`
+	// Tipping rules, nil when the profile doesn't have them. Tips are refused at
+	// checkout when IsTippingAllowedAtCheckout is false, and on carryout orders
+	// when AllowCarryoutTips is false.
+	IsTippingAllowedAtCheckout *bool    `json:"isTippingAllowedAtCheckout"`
+	AllowCarryoutTips          *bool    `json:"allowCarryoutTips"`
+	AcceptableTipPaymentTypes  []string `json:"acceptableTipPaymentTypes"`
+	// market is the market the store was loaded in
+	market *utils.Market
 }
 
 // NewStore creates a new store from store ID
 func NewStore(storeID string) (*Store, error) {
 	store := &Store{
 		StoreID: storeID,
+		market:  utils.CurrentMarket(),
 	}
 
 	// Get store info from API
@@ -50,12 +61,20 @@ func NewStore(storeID string) (*Store, error) {
 	return store, nil
 }
 
-// GetMenu retrieves the menu for this store
+// GetMenu retrieves the menu for this store in lang (the configured language if empty)
 func (s *Store) GetMenu(lang string) (*Menu, error) {
+	return s.GetMenuContext(context.Background(), lang)
+}
+
+// GetMenuContext is GetMenu with a context that cancels the request
+func (s *Store) GetMenuContext(ctx context.Context, lang string) (*Menu, error) {
 	if s.StoreID == "" {
 		return nil, utils.NewDominosStoreError("Store ID is required to get menu")
 	}
 
+	if lang == "" {
+		lang = utils.CurrentLanguage()
+	}
 	if lang == "" {
 		lang = "en"
 	}
@@ -63,11 +82,13 @@ func (s *Store) GetMenu(lang string) (*Menu, error) {
 	menu := &Menu{}
 
 	// Get menu from API
-	url := fmt.Sprintf("https://order.dominos.com/power/store/%s/menu?lang=%s&structured=true",
-		s.StoreID, lang)
+	url := strings.NewReplacer(
+		"${storeID}", s.StoreID,
+		"${lang}", lang,
+	).Replace(utils.URLs.Store.Menu)
 
 	// Create request
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -76,8 +97,8 @@ func (s *Store) GetMenu(lang string) (*Menu, error) {
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/93.0.4577.63 Safari/537.36")
-	req.Header.Set("Origin", "https://order.dominos.com")
-	req.Header.Set("Referer", "https://order.dominos.com/")
+	req.Header.Set("Origin", "https://"+utils.URLs.SourceURI)
+	req.Header.Set("Referer", "https://"+utils.URLs.SourceURI+"/")
 
 	// Send request
 	client := &http.Client{Timeout: time.Second * 30}
@@ -102,6 +123,7 @@ func (s *Store) GetMenu(lang string) (*Menu, error) {
 
 	// Set formatted data in the menu struct
 	menu.SetFormatted(response)
+	menu.Currency = s.Market().Currency
 
 	// Also store the raw response for direct access
 	menu.SetDominosAPIResponse(response)
@@ -109,6 +131,73 @@ func (s *Store) GetMenu(lang string) (*Menu, error) {
 	return menu, nil
 }
 
+// Market returns the market the store was loaded in, or the current market for
+// a store that wasn't loaded with NewStore or NewNearbyStores
+func (s *Store) Market() *utils.Market {
+	if s.market != nil {
+		return s.market
+	}
+	return utils.CurrentMarket()
+}
+
+// AcceptsTip reports whether the store's profile takes tips at checkout for a
+// service method such as "Delivery" or "Carryout". Stores whose profile doesn't
+// have a tipping rule are assumed to accept tips.
+func (s *Store) AcceptsTip(serviceMethod string) bool {
+	if s.IsTippingAllowedAtCheckout != nil && !*s.IsTippingAllowedAtCheckout {
+		return false
+	}
+	if strings.EqualFold(serviceMethod, "Carryout") && s.AllowCarryoutTips != nil {
+		return *s.AllowCarryoutTips
+	}
+	return true
+}
+
+// AcceptsTipPaymentType reports whether a tip can be paid with a payment type.
+// Stores whose profile doesn't list tip payment types are assumed to accept any.
+func (s *Store) AcceptsTipPaymentType(paymentType string) bool {
+	if len(s.AcceptableTipPaymentTypes) == 0 {
+		return true
+	}
+
+	for _, accepted := range s.AcceptableTipPaymentTypes {
+		if strings.EqualFold(accepted, paymentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceptsPaymentType reports whether the store's profile accepts a payment type.
+// Stores whose profile doesn't list payment types are assumed to accept any.
+func (s *Store) AcceptsPaymentType(paymentType string) bool {
+	if len(s.AcceptablePaymentTypes) == 0 {
+		return true
+	}
+
+	for _, accepted := range s.AcceptablePaymentTypes {
+		if strings.EqualFold(accepted, paymentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceptsCardBrand reports whether the store's profile accepts a card brand.
+// Stores whose profile doesn't list credit cards are assumed to accept any.
+func (s *Store) AcceptsCardBrand(brand *CardBrand) bool {
+	if len(s.AcceptableCreditCards) == 0 {
+		return true
+	}
+
+	for _, accepted := range s.AcceptableCreditCards {
+		if strings.EqualFold(accepted, brand.Name) || strings.EqualFold(accepted, brand.CardType) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsCurrentlyOpen checks if this store is currently open
 func (s *Store) IsCurrentlyOpen(serviceMethod string) bool {
 	if !s.IsOpen || !s.IsOnlineCapable || !s.IsOnlineNow {
@@ -136,3 +225,13 @@ func (s *Store) IsCurrentlyOpen(serviceMethod string) bool {
 
 	return false
 }
+
+// GetFormatted returns the store as a map with PascalCase keys
+func (s *Store) GetFormatted() map[string]interface{} {
+	return getFormatted(s)
+}
+
+// SetFormatted updates the store from a map with keys in any format
+func (s *Store) SetFormatted(data map[string]interface{}) {
+	setFormatted(s, data)
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+func TestGetMenuCurrency(t *testing.T) {
+	server := jsonServer(t, func(r *http.Request) interface{} {
+		if strings.HasSuffix(r.URL.Path, "/profile") {
+			return map[string]interface{}{"StoreID": "4336"}
+		}
+		return map[string]interface{}{"Products": map[string]interface{}{}}
+	})
+	useURLs(t, func(urls *utils.URLConfig) {
+		urls.Market = utils.USMarket.Endpoints.Market
+		urls.Store.Info = server.URL + "/${storeID}/profile"
+		urls.Store.Menu = server.URL + "/${storeID}/menu"
+	})
+
+	store, err := NewStore("4336")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The menu is priced in the store's market, not the one in use now
+	utils.URLs.Market = utils.CanadaMarket.Endpoints.Market
+	menu, err := store.GetMenu("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if menu.Currency != "USD" {
+		t.Errorf("Currency = %q, want USD for a US store", menu.Currency)
+	}
+
+	// A store made by hand is in the current market
+	menu, err = (&Store{StoreID: "4336"}).GetMenu("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if menu.Currency != "CAD" {
+		t.Errorf("Currency = %q, want CAD for a store made in Canada", menu.Currency)
+	}
+}
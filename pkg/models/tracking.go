@@ -1,8 +1,10 @@
 package models
 
 import (
-	"fmt"
+	"context"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
 )
@@ -13,8 +15,26 @@ type Tracking struct {
 	OrderID        string `json:"orderID"`
 	Phone          string `json:"phone"`
 	ServiceMethod  string `json:"serviceMethod"`
+	StoreID        string `json:"storeID"`
 	OrderKey       string `json:"orderKey"`
 	PulseOrderGUID string `json:"pulseOrderGUID"`
+	// Market and Language override the active configuration when set
+	Market   string `json:"market"`
+	Language string `json:"language"`
+
+	// WatchTimeout bounds how long Watch polls an order (DefaultWatchTimeout if zero)
+	WatchTimeout time.Duration `json:"-"`
+	// MaxWatchInterval caps the backoff between polls (8x the base interval if zero)
+	MaxWatchInterval time.Duration `json:"-"`
+}
+
+// OrderRef identifies an order to look up in the tracker. Lookups prefer the
+// pulse order GUID, then the store ID and order key, then the phone number.
+type OrderRef struct {
+	Phone          string `json:"phone,omitempty"`
+	StoreID        string `json:"storeID,omitempty"`
+	OrderKey       string `json:"orderKey,omitempty"`
+	PulseOrderGUID string `json:"pulseOrderGUID,omitempty"`
 }
 
 // NewTracking creates a new tracking instance
@@ -22,68 +42,148 @@ func NewTracking() *Tracking {
 	return &Tracking{}
 }
 
-// ByID gets tracking information by order ID
-func (t *Tracking) ByID(orderID string) (map[string]interface{}, error) {
-	if orderID == "" {
-		return nil, utils.NewDominosTrackingError("Order ID is required for tracking")
+// Ref returns the lookup inputs known to this tracking handle
+func (t *Tracking) Ref() OrderRef {
+	return OrderRef{
+		Phone:          t.Phone,
+		StoreID:        t.StoreID,
+		OrderKey:       t.OrderKey,
+		PulseOrderGUID: t.PulseOrderGUID,
 	}
+}
 
-	t.OrderID = orderID
+// Track gets tracking information using the inputs already set on this handle,
+// such as the one returned by Order.Place
+func (t *Tracking) Track() (map[string]interface{}, error) {
+	return t.lookup(context.Background(), t.Ref())
+}
 
-	// Construct the tracking URL
-	var url string
+// ByPhone gets tracking information for the most recent order placed with a phone number
+func (t *Tracking) ByPhone(phone string) (map[string]interface{}, error) {
+	if phone == "" {
+		return nil, utils.NewDominosTrackingError("Phone number is required for tracking")
+	}
+
+	t.Phone = sanitizePhone(phone)
+
+	return t.lookup(context.Background(), OrderRef{Phone: phone})
+}
 
-	// The old method (for Canada) and new method differ
-	if strings.Contains(utils.URLs.Track, "orderstorage") {
-		// Old method (e.g., Canada)
-		url = fmt.Sprintf("%sOrderKey=%s", utils.URLs.Track, orderID)
-	} else {
-		// New method (e.g., USA)
-		url = fmt.Sprintf("%s/%s/%s", utils.URLs.TrackRoot, utils.URLs.Track, orderID)
+// ByOrderKey gets tracking information by store ID and the store's order key
+func (t *Tracking) ByOrderKey(storeID string, orderKey string) (map[string]interface{}, error) {
+	if storeID == "" || orderKey == "" {
+		return nil, utils.NewDominosTrackingError("Store ID and order key are required for tracking")
 	}
 
-	// Make the tracking request
-	response, err := utils.GetTracking(url, "")
+	t.StoreID = storeID
+	t.OrderKey = orderKey
+
+	return t.lookup(context.Background(), OrderRef{StoreID: storeID, OrderKey: orderKey})
+}
+
+// ByPulseOrderGUID gets tracking information by the pulse order GUID returned when placing an order
+func (t *Tracking) ByPulseOrderGUID(guid string) (map[string]interface{}, error) {
+	if guid == "" {
+		return nil, utils.NewDominosTrackingError("Pulse order GUID is required for tracking")
+	}
+
+	t.PulseOrderGUID = guid
+
+	return t.lookup(context.Background(), OrderRef{PulseOrderGUID: guid})
+}
+
+// lookup fetches tracking information for ref without modifying the Tracking,
+// so it can be shared by concurrent watchers
+func (t *Tracking) lookup(ctx context.Context, ref OrderRef) (map[string]interface{}, error) {
+	var template string
+	var replacements []string
+
+	switch {
+	case ref.PulseOrderGUID != "":
+		template = utils.URLs.Tracker.PulseGUID
+		replacements = []string{"${pulseOrderGUID}", escapeTrackerValue(template, "${pulseOrderGUID}", ref.PulseOrderGUID)}
+	case ref.StoreID != "" && ref.OrderKey != "":
+		template = utils.URLs.Tracker.OrderKey
+		replacements = []string{
+			"${storeID}", escapeTrackerValue(template, "${storeID}", ref.StoreID),
+			"${orderKey}", escapeTrackerValue(template, "${orderKey}", ref.OrderKey),
+		}
+	case ref.Phone != "":
+		template = utils.URLs.Tracker.Phone
+		replacements = []string{"${phone}", sanitizePhone(ref.Phone)}
+	default:
+		return nil, utils.NewDominosTrackingError("Phone number, store ID and order key, or pulse order GUID is required for tracking")
+	}
+
+	if template == "" {
+		return nil, utils.NewDominosTrackingError("This tracking lookup is not supported for the current market")
+	}
+
+	// Make the tracking request the way the current market's tracker expects
+	fetch := utils.GetTrackingContext
+	if utils.CurrentMarket().Tracking == utils.OrderStorageTracker {
+		fetch = utils.GetTrackerData
+	}
+
+	response, err := fetch(ctx, strings.NewReplacer(replacements...).Replace(template), t.Market, t.Language)
 	if err != nil {
 		return nil, err
 	}
 
+	// Phone lookups list the matching orders, each linking to its own tracker
+	if orders, ok := response["Orders"].([]interface{}); ok {
+		if len(orders) == 0 {
+			return nil, utils.NewDominosTrackingError("No orders found to track")
+		}
+
+		order, _ := orders[0].(map[string]interface{})
+		actions, _ := order["Actions"].(map[string]interface{})
+		link, _ := actions["Track"].(string)
+		if link == "" {
+			return order, nil
+		}
+
+		return utils.GetTrackingContext(ctx, resolveTrackerLink(link), t.Market, t.Language)
+	}
+
 	return response, nil
 }
 
-// ByPhone gets tracking information by phone number
-func (t *Tracking) ByPhone(phone string) (map[string]interface{}, error) {
-	if phone == "" {
-		return nil, utils.NewDominosTrackingError("Phone number is required for tracking")
+// escapeTrackerValue escapes a value for the part of a tracker URL template its
+// placeholder is in, the path or the query string
+func escapeTrackerValue(template string, placeholder string, value string) string {
+	query := strings.Index(template, "?")
+	if query >= 0 && strings.Index(template, placeholder) > query {
+		return url.QueryEscape(value)
+	}
+	return url.PathEscape(value)
+}
+
+// resolveTrackerLink turns a tracker link relative to TrackRoot into an absolute URL
+func resolveTrackerLink(link string) string {
+	if strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://") {
+		return link
 	}
+	return strings.TrimSuffix(utils.URLs.TrackRoot, "/") + "/" + strings.TrimPrefix(link, "/")
+}
 
-	// Sanitize phone number (remove non-digits)
+// sanitizePhone removes every non-digit from a phone number
+func sanitizePhone(phone string) string {
 	sanitizedPhone := ""
 	for _, char := range phone {
 		if char >= '0' && char <= '9' {
 			sanitizedPhone += string(char)
 		}
 	}
+	return sanitizedPhone
+}
 
-	t.Phone = sanitizedPhone
-
-	// Construct the tracking URL
-	var url string
-
-	// The old method (for Canada) and new method differ
-	if strings.Contains(utils.URLs.Track, "orderstorage") {
-		// Old method (e.g., Canada)
-		url = fmt.Sprintf("%sPhone=%s", utils.URLs.Track, sanitizedPhone)
-	} else {
-		// New method (e.g., USA)
-		url = fmt.Sprintf("%s/%s/phone/%s", utils.URLs.TrackRoot, utils.URLs.Track, sanitizedPhone)
-	}
-
-	// Make the tracking request
-	response, err := utils.GetTracking(url, "")
-	if err != nil {
-		return nil, err
-	}
+// GetFormatted returns the tracking as a map with PascalCase keys
+func (t *Tracking) GetFormatted() map[string]interface{} {
+	return getFormatted(t)
+}
 
-	return response, nil
+// SetFormatted updates the tracking from a map with keys in any format
+func (t *Tracking) SetFormatted(data map[string]interface{}) {
+	setFormatted(t, data)
 }
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+func TestEscapeTrackerValue(t *testing.T) {
+	tests := []struct {
+		template    string
+		placeholder string
+		value       string
+		want        string
+	}{
+		{utils.USA.Tracker.OrderKey, "${orderKey}", "a b/c&d", "a%20b%2Fc&d"},
+		{utils.Canada.Tracker.OrderKey, "${orderKey}", "a b/c&d", "a+b%2Fc%26d"},
+		{utils.Canada.Tracker.OrderKey, "${storeID}", "10 1", "10+1"},
+		{utils.USA.Tracker.PulseGUID, "${pulseOrderGUID}", "guid?x", "guid%3Fx"},
+	}
+	for _, test := range tests {
+		if got := escapeTrackerValue(test.template, test.placeholder, test.value); got != test.want {
+			t.Errorf("escapeTrackerValue(%s, %s, %q) = %q, want %q", test.template, test.placeholder, test.value, got, test.want)
+		}
+	}
+}
+
+func TestTrackingByOrderKeyQuery(t *testing.T) {
+	var query map[string][]string
+	server := jsonServer(t, func(r *http.Request) interface{} {
+		query = r.URL.Query()
+		return map[string]interface{}{"OrderStatus": "Oven"}
+	})
+	useURLs(t, func(urls *utils.URLConfig) {
+		urls.Tracker.OrderKey = server.URL + "/GetTrackerData?StoreID=${storeID}&OrderKey=${orderKey}"
+	})
+
+	response, err := NewTracking().ByOrderKey("7890", "12#34&5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trackingStatus(response) != "Oven" {
+		t.Errorf("response = %v", response)
+	}
+	if query["StoreID"][0] != "7890" || query["OrderKey"][0] != "12#34&5" {
+		t.Errorf("query = %v, want the store ID and order key intact", query)
+	}
+}
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// Polling defaults used by Watch
+const (
+	DefaultWatchInterval = 30 * time.Second
+	DefaultWatchTimeout  = 2 * time.Hour
+
+	// watchBackoffCap is how many times the base interval the backoff may grow to
+	watchBackoffCap = 8
+)
+
+// Tracker statuses after which an order no longer changes
+var trackingTerminalStatuses = []string{"Complete", "Cancelled", "Canceled", "Void"}
+
+// TrackingEvent is emitted by Watch whenever an order's tracking status changes
+type TrackingEvent struct {
+	Ref            OrderRef
+	Status         string
+	PreviousStatus string
+	Response       map[string]interface{}
+	Time           time.Time
+	// Err is set when a poll fails or the watch times out
+	Err error
+	// Done is set on the last event sent before the channel is closed
+	Done bool
+}
+
+// Watch polls the tracker for ref and emits an event on the returned channel each time
+// the order's status changes. Polls of an unchanged status (or failed polls) back off
+// from interval up to MaxWatchInterval; a change resets the interval. The channel is
+// closed once the order reaches a terminal status, ctx is done or WatchTimeout elapses.
+// Watch does not modify t, so one Tracking can watch many orders concurrently.
+func (t *Tracking) Watch(ctx context.Context, ref OrderRef, interval time.Duration) <-chan TrackingEvent {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	timeout := t.WatchTimeout
+	if timeout <= 0 {
+		timeout = DefaultWatchTimeout
+	}
+
+	maxInterval := t.MaxWatchInterval
+	if maxInterval <= 0 {
+		maxInterval = interval * watchBackoffCap
+	}
+	if maxInterval < interval {
+		maxInterval = interval
+	}
+
+	events := make(chan TrackingEvent)
+
+	go func() {
+		defer close(events)
+
+		parent := ctx
+		deadline := time.Now().Add(timeout)
+		ctx, cancel := context.WithDeadline(parent, deadline)
+		defer cancel()
+
+		// send delivers an event unless the watch has been stopped
+		send := func(event TrackingEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		status := ""
+		delay := interval
+
+		for {
+			response, err := t.lookup(ctx, ref)
+
+			changed := false
+			if err != nil {
+				if ctx.Err() != nil {
+					break
+				}
+				if !send(TrackingEvent{Ref: ref, Status: status, PreviousStatus: status, Time: time.Now(), Err: err}) {
+					break
+				}
+			} else if current := trackingStatus(response); current != status {
+				changed = true
+				event := TrackingEvent{
+					Ref:            ref,
+					Status:         current,
+					PreviousStatus: status,
+					Response:       response,
+					Time:           time.Now(),
+					Done:           isTerminalStatus(current),
+				}
+				status = current
+				if !send(event) || event.Done {
+					return
+				}
+			}
+
+			// Adapt the polling interval
+			if changed {
+				delay = interval
+			} else {
+				delay += delay / 2
+				if delay > maxInterval {
+					delay = maxInterval
+				}
+			}
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+			case <-timer.C:
+				continue
+			}
+			break
+		}
+
+		// Report a timeout to the consumer; plain cancellation just closes the channel.
+		// A consumer that stopped reading gets one more interval to take the event so
+		// the goroutine can't block forever.
+		if parent.Err() == nil && !time.Now().Before(deadline) {
+			ctx, cancel := context.WithTimeout(parent, maxInterval)
+			defer cancel()
+
+			select {
+			case events <- TrackingEvent{
+				Ref:            ref,
+				Status:         status,
+				PreviousStatus: status,
+				Time:           time.Now(),
+				Err:            utils.NewDominosTrackingError("Timed out waiting for order to complete"),
+				Done:           true,
+			}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	return events
+}
+
+// trackingStatus extracts the order status from a tracker response
+func trackingStatus(response map[string]interface{}) string {
+	for _, key := range []string{"OrderStatus", "Status"} {
+		if status, ok := response[key].(string); ok {
+			return status
+		}
+	}
+	return ""
+}
+
+// isTerminalStatus reports whether the order can no longer change status
+func isTerminalStatus(status string) bool {
+	for _, terminal := range trackingTerminalStatuses {
+		if strings.EqualFold(status, terminal) {
+			return true
+		}
+	}
+	return false
+}
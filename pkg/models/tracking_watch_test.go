@@ -0,0 +1,139 @@
+package models
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// trackerStub serves tracker responses with the given statuses in turn,
+// repeating the last one
+func trackerStub(t *testing.T, statuses ...string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	polls := 0
+	server := jsonServer(t, func(r *http.Request) interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+
+		status := statuses[len(statuses)-1]
+		if polls < len(statuses) {
+			status = statuses[polls]
+		}
+		polls++
+		return map[string]interface{}{"OrderStatus": status}
+	})
+
+	useURLs(t, func(urls *utils.URLConfig) {
+		urls.Tracker.PulseGUID = server.URL + "/orders/${pulseOrderGUID}"
+	})
+}
+
+func collectEvents(t *testing.T, events <-chan TrackingEvent) []TrackingEvent {
+	t.Helper()
+
+	collected := make([]TrackingEvent, 0)
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return collected
+			}
+			collected = append(collected, event)
+		case <-timeout:
+			t.Fatal("Watch did not close its channel")
+		}
+	}
+}
+
+func TestWatchEmitsChangesUntilComplete(t *testing.T) {
+	trackerStub(t, "Makeline", "Makeline", "Oven", "Oven", "Complete")
+
+	ref := OrderRef{PulseOrderGUID: "guid"}
+	events := collectEvents(t, NewTracking().Watch(context.Background(), ref, time.Millisecond))
+
+	want := []string{"Makeline", "Oven", "Complete"}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, event := range events {
+		if event.Status != want[i] {
+			t.Errorf("event %d status = %q, want %q", i, event.Status, want[i])
+		}
+		if i > 0 && event.PreviousStatus != want[i-1] {
+			t.Errorf("event %d previous status = %q, want %q", i, event.PreviousStatus, want[i-1])
+		}
+		if event.Ref != ref {
+			t.Errorf("event %d ref = %+v, want %+v", i, event.Ref, ref)
+		}
+		if event.Done != (i == len(want)-1) {
+			t.Errorf("event %d done = %v", i, event.Done)
+		}
+	}
+}
+
+func TestWatchTimesOut(t *testing.T) {
+	trackerStub(t, "Makeline")
+
+	tracking := &Tracking{WatchTimeout: 20 * time.Millisecond}
+	events := collectEvents(t, tracking.Watch(context.Background(), OrderRef{PulseOrderGUID: "guid"}, time.Millisecond))
+
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2: %+v", len(events), events)
+	}
+	last := events[1]
+	if last.Err == nil || !last.Done || last.Status != "Makeline" {
+		t.Errorf("last event = %+v, want a timeout error", last)
+	}
+}
+
+func TestWatchTimeoutWithoutConsumer(t *testing.T) {
+	trackerStub(t, "Makeline")
+
+	tracking := &Tracking{WatchTimeout: 10 * time.Millisecond, MaxWatchInterval: 10 * time.Millisecond}
+	events := tracking.Watch(context.Background(), OrderRef{PulseOrderGUID: "guid"}, time.Millisecond)
+
+	// Nothing reads the events, so the watch has to give up on delivering them
+	time.Sleep(200 * time.Millisecond)
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("Watch still had an event to deliver after its timeout")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Watch did not close its channel")
+	}
+}
+
+func TestWatchCancel(t *testing.T) {
+	trackerStub(t, "Makeline")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := NewTracking().Watch(ctx, OrderRef{PulseOrderGUID: "guid"}, time.Millisecond)
+
+	if first := <-events; first.Status != "Makeline" {
+		t.Fatalf("first event status = %q, want Makeline", first.Status)
+	}
+	cancel()
+
+	for _, event := range collectEvents(t, events) {
+		t.Errorf("unexpected event after cancel: %+v", event)
+	}
+}
+
+func TestWatchReportsFailedPolls(t *testing.T) {
+	useURLs(t, func(urls *utils.URLConfig) { urls.Tracker.PulseGUID = "" })
+
+	tracking := &Tracking{WatchTimeout: 10 * time.Millisecond}
+	events := collectEvents(t, tracking.Watch(context.Background(), OrderRef{PulseOrderGUID: "guid"}, time.Millisecond))
+
+	if len(events) == 0 || events[0].Err == nil {
+		t.Fatalf("events = %+v, want a lookup error first", events)
+	}
+}
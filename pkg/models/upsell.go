@@ -0,0 +1,146 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// UpsellSuggestion is a product the upsell service suggests adding to an order
+type UpsellSuggestion struct {
+	// ProductCode is the menu product, such as F_PARMT
+	ProductCode string
+	// Code is the variant added to the order
+	Code    string
+	Name    string
+	Reason  string
+	Qty     int
+	Options map[string]interface{}
+	// PriceDelta is how much adding the suggestion raises the order's price
+	PriceDelta Money
+}
+
+// GetUpsells posts the current cart to the upsell service and returns its suggestions
+func (o *Order) GetUpsells() ([]*UpsellSuggestion, error) {
+	return o.requestUpsells(utils.URLs.Upsell)
+}
+
+// GetStepUpsells posts the current cart to the step upsell service, which suggests
+// products for the next step of checkout, and returns its suggestions
+func (o *Order) GetStepUpsells() ([]*UpsellSuggestion, error) {
+	return o.requestUpsells(utils.URLs.StepUpsell)
+}
+
+// AddUpsell adds a suggestion to the order and returns the new item
+func (o *Order) AddUpsell(suggestion *UpsellSuggestion) (*Item, error) {
+	item, err := suggestion.Item()
+	if err != nil {
+		return nil, err
+	}
+
+	o.AddItem(item)
+	return item, nil
+}
+
+// Item creates an order item from the suggestion
+func (s *UpsellSuggestion) Item() (*Item, error) {
+	code := s.Code
+	if code == "" {
+		code = s.ProductCode
+	}
+	if code == "" {
+		return nil, utils.NewDominosProductsError("Upsell suggestion has no product code")
+	}
+
+	qty := s.Qty
+	if qty < 1 {
+		qty = 1
+	}
+
+	item, err := NewItem(map[string]interface{}{"code": code, "qty": qty})
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range s.Options {
+		item.Options[key] = value
+	}
+	return item, nil
+}
+
+// requestUpsells posts the order to an upsell endpoint and reads the suggestions
+func (o *Order) requestUpsells(url string) ([]*UpsellSuggestion, error) {
+	if url == "" {
+		return nil, utils.NewDominosMarketError("The current market has no upsell endpoint")
+	}
+	if o.StoreID == "" {
+		return nil, utils.NewDominosStoreError("Store ID must be set before requesting upsells")
+	}
+
+	// Create payload
+	payload := map[string]interface{}{
+		"Order": o.GetFormatted(),
+	}
+
+	products := make([]map[string]interface{}, len(o.Products))
+	for i, item := range o.Products {
+		products[i] = item.GetFormatted()
+	}
+	payload["Order"].(map[string]interface{})["Products"] = products
+	delete(payload["Order"].(map[string]interface{}), "Payments")
+
+	url = strings.Replace(url, "${storeID}", o.StoreID, -1)
+	response, err := utils.Post(url, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if status, ok := response["Status"].(float64); ok && status == -1 {
+		return nil, utils.NewDominosProductsError(response)
+	}
+
+	suggestions := make([]*UpsellSuggestion, 0)
+	upsells, _ := response["Upsells"].([]interface{})
+	for _, data := range upsells {
+		if data, ok := data.(map[string]interface{}); ok {
+			suggestions = append(suggestions, o.newUpsellSuggestion(data))
+		}
+	}
+	return suggestions, nil
+}
+
+// newUpsellSuggestion reads a suggestion from an upsell response. Both upsell
+// services are expected to answer with this shape, which TestGetUpsellsLive
+// checks against a real store and records as testdata/upsell.json:
+//
+//	{
+//	  "Status": 0,
+//	  "Upsells": [{
+//	    "ProductCode": "F_PARMT",
+//	    "Code": "P_PARMT",
+//	    "Name": "Parmesan Bread Twists",
+//	    "Reason": "Goes great with pizza",
+//	    "Qty": 1,
+//	    "Options": {},
+//	    "Price": "3.99"
+//	  }]
+//	}
+//
+// Price is what adding the suggestion raises the order's price by.
+func (o *Order) newUpsellSuggestion(data map[string]interface{}) *UpsellSuggestion {
+	suggestion := &UpsellSuggestion{Qty: 1}
+
+	suggestion.ProductCode, _ = data["ProductCode"].(string)
+	suggestion.Code, _ = data["Code"].(string)
+	suggestion.Name, _ = data["Name"].(string)
+	suggestion.Reason, _ = data["Reason"].(string)
+	if qty := intValue(data["Qty"]); qty > 0 {
+		suggestion.Qty = qty
+	}
+	suggestion.Options, _ = data["Options"].(map[string]interface{})
+
+	if price, err := menuPrice(data["Price"], o.Currency); err == nil {
+		suggestion.PriceDelta = price
+	}
+
+	return suggestion
+}
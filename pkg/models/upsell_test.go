@@ -0,0 +1,99 @@
+package models
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+func TestGetUpsells(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/upsell.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	useURLs(t, func(urls *utils.URLConfig) {
+		urls.Upsell = server.URL + "/upsell-service/stores/upsellForOrder/${storeID}"
+		urls.StepUpsell = server.URL + "/upsell-service/stores/stepUpsellForOrder/${storeID}"
+	})
+
+	order := &Order{StoreID: "4336", Currency: "USD"}
+	suggestions, err := order.GetUpsells()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/upsell-service/stores/upsellForOrder/4336" {
+		t.Errorf("requested %q", path)
+	}
+
+	if len(suggestions) != 2 {
+		t.Fatalf("got %d suggestions, want 2", len(suggestions))
+	}
+	bread := suggestions[0]
+	if bread.ProductCode != "F_PARMT" || bread.Code != "P_PARMT" || bread.Name != "Parmesan Bread Twists" || bread.Reason != "Goes great with pizza" {
+		t.Errorf("suggestion = %+v", bread)
+	}
+	if bread.PriceDelta != NewMoney(399, "USD") {
+		t.Errorf("PriceDelta = %v, want 3.99 USD", bread.PriceDelta)
+	}
+
+	item, err := order.AddUpsell(suggestions[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Code != "2LCOKE" || item.Qty != 2 || len(order.Products) != 1 {
+		t.Errorf("added %+v", item)
+	}
+
+	if _, err := order.GetStepUpsells(); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/upsell-service/stores/stepUpsellForOrder/4336" {
+		t.Errorf("step upsells requested %q", path)
+	}
+}
+
+// TestGetUpsellsLive checks the upsell response shape against a real store, and
+// records it as testdata/upsell.json when DOMINOS_RECORD is set:
+//
+//	DOMINOS_LIVE_STORE=4336 DOMINOS_RECORD=1 go test ./pkg/models -run Live
+func TestGetUpsellsLive(t *testing.T) {
+	storeID := liveStoreID(t)
+	lastResponse := recordResponses(t)
+
+	order := &Order{StoreID: storeID, ServiceMethod: "Carryout", Currency: utils.CurrentMarket().Currency}
+	order.AddItem(&Item{Code: "14SCREEN", Qty: 1})
+
+	suggestions, err := order.GetUpsells()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := lastResponse()
+	var response map[string]interface{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := response["Upsells"]; !ok {
+		t.Fatalf("response has no Upsells list: %s", body)
+	}
+	for _, suggestion := range suggestions {
+		if suggestion.Code == "" && suggestion.ProductCode == "" {
+			t.Errorf("suggestion without a code in %s", body)
+		}
+	}
+
+	saveFixture(t, "upsell.json", body)
+}
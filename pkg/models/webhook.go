@@ -0,0 +1,278 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zjpiazza/go-dominos-pizza-api/pkg/utils"
+)
+
+// WebhookSignatureHeader carries the HMAC-SHA256 signature of a webhook body
+const WebhookSignatureHeader = "X-Dominos-Signature"
+
+// Delivery defaults used by WebhookDispatcher
+const (
+	DefaultWebhookAttempts   = 3
+	DefaultWebhookRetryDelay = time.Second
+)
+
+// WebhookEvent is the JSON body POSTed to a callback URL on each stage change
+type WebhookEvent struct {
+	Ref            OrderRef  `json:"orderRef"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previousStatus"`
+	Time           time.Time `json:"time"`
+	Done           bool      `json:"done"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// WebhookDelivery records a webhook that could not be delivered
+type WebhookDelivery struct {
+	CallbackURL string
+	Event       WebhookEvent
+	Attempts    int
+	LastError   error
+}
+
+// WebhookDispatcher polls tracking for registered orders in the background and
+// POSTs signed events to each order's callback URL when its status changes
+type WebhookDispatcher struct {
+	Tracking    *Tracking
+	Secret      []byte
+	Interval    time.Duration
+	MaxAttempts int
+	RetryDelay  time.Duration
+	Client      *http.Client
+
+	mu            sync.Mutex
+	ctx           context.Context
+	cancel        context.CancelFunc
+	subscriptions map[OrderRef]*webhookSubscription
+	deadLetters   []*WebhookDelivery
+	wg            sync.WaitGroup
+}
+
+// webhookSubscription is a registered order's running watch
+type webhookSubscription struct {
+	cancel context.CancelFunc
+}
+
+// NewWebhookDispatcher creates a dispatcher that signs events with secret
+func NewWebhookDispatcher(tracking *Tracking, secret []byte) *WebhookDispatcher {
+	if tracking == nil {
+		tracking = NewTracking()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &WebhookDispatcher{
+		Tracking:      tracking,
+		Secret:        secret,
+		Interval:      DefaultWatchInterval,
+		MaxAttempts:   DefaultWebhookAttempts,
+		RetryDelay:    DefaultWebhookRetryDelay,
+		ctx:           ctx,
+		cancel:        cancel,
+		subscriptions: make(map[OrderRef]*webhookSubscription),
+	}
+}
+
+// Register starts watching ref and delivering its status changes to callbackURL
+func (d *WebhookDispatcher) Register(ref OrderRef, callbackURL string) error {
+	if callbackURL == "" {
+		return utils.NewDominosTrackingError("Callback URL is required for webhook delivery")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.ctx.Err() != nil {
+		return utils.NewDominosTrackingError("Webhook dispatcher is closed")
+	}
+	if _, ok := d.subscriptions[ref]; ok {
+		return utils.NewDominosTrackingError("Order is already registered for webhooks")
+	}
+
+	ctx, cancel := context.WithCancel(d.ctx)
+	subscription := &webhookSubscription{cancel: cancel}
+	d.subscriptions[ref] = subscription
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer d.remove(ref, subscription)
+
+		for event := range d.Tracking.Watch(ctx, ref, d.Interval) {
+			// Failed polls are retried by Watch and are not stage changes
+			if event.Err != nil && !event.Done {
+				continue
+			}
+
+			webhookEvent := WebhookEvent{
+				Ref:            event.Ref,
+				Status:         event.Status,
+				PreviousStatus: event.PreviousStatus,
+				Time:           event.Time,
+				Done:           event.Done,
+			}
+			if event.Err != nil {
+				webhookEvent.Error = event.Err.Error()
+			}
+
+			d.deliver(ctx, callbackURL, webhookEvent)
+		}
+	}()
+
+	return nil
+}
+
+// Unregister stops watching ref. The order can be registered again right away.
+func (d *WebhookDispatcher) Unregister(ref OrderRef) {
+	d.mu.Lock()
+	subscription, ok := d.subscriptions[ref]
+	delete(d.subscriptions, ref)
+	d.mu.Unlock()
+
+	if ok {
+		subscription.cancel()
+	}
+}
+
+// Registered returns the orders currently being watched
+func (d *WebhookDispatcher) Registered() []OrderRef {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	refs := make([]OrderRef, 0, len(d.subscriptions))
+	for ref := range d.subscriptions {
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// DeadLetters returns the events that exhausted their delivery attempts
+func (d *WebhookDispatcher) DeadLetters() []*WebhookDelivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]*WebhookDelivery(nil), d.deadLetters...)
+}
+
+// Close stops every watch and waits for its goroutines to exit. Deliveries
+// interrupted by Close end up in DeadLetters.
+func (d *WebhookDispatcher) Close() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// remove forgets a finished subscription, unless ref has since been registered again
+func (d *WebhookDispatcher) remove(ref OrderRef, subscription *webhookSubscription) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	subscription.cancel()
+	if d.subscriptions[ref] == subscription {
+		delete(d.subscriptions, ref)
+	}
+}
+
+// deliver POSTs event to callbackURL, retrying with exponential backoff and
+// dead-lettering it once all attempts fail
+func (d *WebhookDispatcher) deliver(ctx context.Context, callbackURL string, event WebhookEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		d.deadLetter(callbackURL, event, 0, err)
+		return
+	}
+
+	attempts := d.MaxAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+	delay := d.RetryDelay
+
+	for attempt := 1; ; attempt++ {
+		err = d.post(ctx, callbackURL, body)
+		if err == nil {
+			return
+		}
+
+		if attempt >= attempts || ctx.Err() != nil {
+			d.deadLetter(callbackURL, event, attempt, err)
+			return
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			d.deadLetter(callbackURL, event, attempt, err)
+			return
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// post sends one signed webhook request
+func (d *WebhookDispatcher) post(ctx context.Context, callbackURL string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(d.Secret, body))
+
+	client := d.Client
+	if client == nil {
+		client = utils.Client
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook receiver responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// deadLetter records an undeliverable event
+func (d *WebhookDispatcher) deadLetter(callbackURL string, event WebhookEvent, attempts int, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.deadLetters = append(d.deadLetters, &WebhookDelivery{
+		CallbackURL: callbackURL,
+		Event:       event,
+		Attempts:    attempts,
+		LastError:   err,
+	})
+}
+
+// SignWebhookPayload returns the signature header value for a webhook body
+func SignWebhookPayload(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature reports whether signature matches body, for use by receivers
+func VerifyWebhookSignature(secret, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhookPayload(secret, body)), []byte(signature))
+}
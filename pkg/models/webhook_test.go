@@ -0,0 +1,196 @@
+package models
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookReceiver records the events POSTed to it, failing the first failures
+// requests with a 500
+type webhookReceiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	events   []WebhookEvent
+	requests int
+	failures int
+	received chan WebhookEvent
+}
+
+func newWebhookReceiver(t *testing.T, secret []byte, failures int) *webhookReceiver {
+	t.Helper()
+
+	receiver := &webhookReceiver{failures: failures, received: make(chan WebhookEvent, 16)}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !VerifyWebhookSignature(secret, body, r.Header.Get(WebhookSignatureHeader)) {
+			t.Errorf("webhook signature %q doesn't match its body", r.Header.Get(WebhookSignatureHeader))
+		}
+
+		receiver.mu.Lock()
+		receiver.requests++
+		if receiver.requests <= receiver.failures {
+			receiver.mu.Unlock()
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var event WebhookEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Errorf("webhook body isn't an event: %v", err)
+		}
+		receiver.events = append(receiver.events, event)
+		receiver.mu.Unlock()
+
+		receiver.received <- event
+	}))
+	t.Cleanup(receiver.Close)
+	return receiver
+}
+
+func (r *webhookReceiver) next(t *testing.T) WebhookEvent {
+	t.Helper()
+
+	select {
+	case event := <-r.received:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook was delivered")
+	}
+	return WebhookEvent{}
+}
+
+func TestWebhookDispatcherDeliversSignedEvents(t *testing.T) {
+	trackerStub(t, "Makeline", "Oven", "Complete")
+	secret := []byte("secret")
+	receiver := newWebhookReceiver(t, secret, 1)
+
+	dispatcher := NewWebhookDispatcher(nil, secret)
+	dispatcher.Interval = time.Millisecond
+	dispatcher.RetryDelay = time.Millisecond
+	defer dispatcher.Close()
+
+	ref := OrderRef{PulseOrderGUID: "guid"}
+	if err := dispatcher.Register(ref, receiver.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"Makeline", "Oven", "Complete"} {
+		event := receiver.next(t)
+		if event.Status != want || event.Ref != ref {
+			t.Errorf("event = %+v, want status %s", event, want)
+		}
+		if event.Done != (want == "Complete") {
+			t.Errorf("event %s done = %v", want, event.Done)
+		}
+	}
+
+	// The watch ends with the order, which is then no longer registered
+	deadline := time.Now().Add(5 * time.Second)
+	for len(dispatcher.Registered()) != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if len(dispatcher.Registered()) != 0 {
+		t.Errorf("finished orders still registered: %v", dispatcher.Registered())
+	}
+	if len(dispatcher.DeadLetters()) != 0 {
+		t.Errorf("retried delivery was dead-lettered: %+v", dispatcher.DeadLetters()[0])
+	}
+}
+
+func TestWebhookDispatcherDeadLetters(t *testing.T) {
+	trackerStub(t, "Complete")
+	receiver := newWebhookReceiver(t, nil, 100)
+
+	dispatcher := NewWebhookDispatcher(nil, nil)
+	dispatcher.Interval = time.Millisecond
+	dispatcher.RetryDelay = time.Millisecond
+	dispatcher.MaxAttempts = 2
+
+	if err := dispatcher.Register(OrderRef{PulseOrderGUID: "guid"}, receiver.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	// The order completes on the first poll, so its goroutine exits after delivering
+	deadline := time.Now().Add(5 * time.Second)
+	for len(dispatcher.DeadLetters()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	dispatcher.Close()
+
+	letters := dispatcher.DeadLetters()
+	if len(letters) != 1 {
+		t.Fatalf("got %d dead letters, want 1", len(letters))
+	}
+	if letters[0].Attempts != 2 || letters[0].LastError == nil || letters[0].Event.Status != "Complete" {
+		t.Errorf("dead letter = %+v", letters[0])
+	}
+}
+
+func TestWebhookDispatcherReregister(t *testing.T) {
+	trackerStub(t, "Makeline")
+	receiver := newWebhookReceiver(t, nil, 0)
+
+	dispatcher := NewWebhookDispatcher(nil, nil)
+	dispatcher.Interval = time.Millisecond
+	defer dispatcher.Close()
+
+	ref := OrderRef{PulseOrderGUID: "guid"}
+	if err := dispatcher.Register(ref, receiver.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := dispatcher.Register(ref, receiver.URL); err == nil {
+		t.Error("registering an order twice succeeded")
+	}
+	receiver.next(t)
+
+	dispatcher.Unregister(ref)
+	if err := dispatcher.Register(ref, receiver.URL); err != nil {
+		t.Fatalf("registering again after Unregister: %v", err)
+	}
+
+	// The first watch exiting must not forget the second registration
+	receiver.next(t)
+	time.Sleep(10 * time.Millisecond)
+	if registered := dispatcher.Registered(); len(registered) != 1 || registered[0] != ref {
+		t.Errorf("registered = %v, want [%v]", registered, ref)
+	}
+}
+
+func TestWebhookDispatcherClosed(t *testing.T) {
+	dispatcher := NewWebhookDispatcher(nil, nil)
+	dispatcher.Close()
+
+	if err := dispatcher.Register(OrderRef{Phone: "5555555555"}, "http://localhost/hook"); err == nil {
+		t.Error("registering with a closed dispatcher succeeded")
+	}
+	if err := NewWebhookDispatcher(nil, nil).Register(OrderRef{Phone: "5555555555"}, ""); err == nil {
+		t.Error("registering without a callback URL succeeded")
+	}
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"status":"Oven"}`)
+	signature := SignWebhookPayload([]byte("secret"), body)
+
+	tests := []struct {
+		secret    string
+		body      string
+		signature string
+		want      bool
+	}{
+		{"secret", string(body), signature, true},
+		{"other", string(body), signature, false},
+		{"secret", `{"status":"Complete"}`, signature, false},
+		{"secret", string(body), "", false},
+	}
+	for _, test := range tests {
+		if got := VerifyWebhookSignature([]byte(test.secret), []byte(test.body), test.signature); got != test.want {
+			t.Errorf("VerifyWebhookSignature(%q, %s, %q) = %v, want %v", test.secret, test.body, test.signature, got, test.want)
+		}
+	}
+}
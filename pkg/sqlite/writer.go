@@ -0,0 +1,305 @@
+// Package sqlite writes SQLite 3 database files holding plain tables, without
+// indexes or constraints, so callers need no SQLite library or cgo
+package sqlite
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// SQLite file format constants. See https://www.sqlite.org/fileformat.html
+const (
+	pageSize       = 4096
+	fileHeaderSize = 100
+	leafTable      = 0x0d
+	interiorTable  = 0x05
+	version        = 3040000
+)
+
+// Writer builds a SQLite 3 database file in memory. Tables are written in the
+// order they're added, with rowids counting from 1.
+type Writer struct {
+	pages  [][]byte
+	master [][]interface{}
+}
+
+// tableCell is a table b-tree cell with the largest rowid under it
+type tableCell struct {
+	data  []byte
+	rowid int64
+}
+
+// treeNode is a written b-tree page with the largest rowid under it
+type treeNode struct {
+	page  int
+	rowid int64
+}
+
+// NewWriter creates a writer, reserving page 1 for the schema
+func NewWriter() *Writer {
+	w := &Writer{}
+	w.allocate()
+	return w
+}
+
+// allocate adds an empty page, returning its number
+func (w *Writer) allocate() (int, []byte) {
+	page := make([]byte, pageSize)
+	w.pages = append(w.pages, page)
+	return len(w.pages), page
+}
+
+// AddTable writes a table's rows and records it in the schema with its CREATE
+// TABLE statement. Row values are strings, int64s, float64s or nils.
+func (w *Writer) AddTable(name string, sql string, rows [][]interface{}) {
+	cells := make([]tableCell, 0, len(rows))
+	for i, row := range rows {
+		cells = append(cells, w.leafCell(int64(i+1), encodeRecord(row)))
+	}
+
+	nodes := w.writeLeaves(cells)
+	for len(nodes) > 1 {
+		nodes = w.writeInteriors(nodes)
+	}
+
+	w.master = append(w.master, []interface{}{"table", name, name, int64(nodes[0].page), sql})
+}
+
+// Bytes returns the database file, or false if the schema doesn't fit on page 1
+func (w *Writer) Bytes() ([]byte, bool) {
+	cells := make([][]byte, 0, len(w.master))
+	size := fileHeaderSize + 8
+	for i, row := range w.master {
+		record := encodeRecord(row)
+		cell := append(varint(uint64(len(record))), varint(uint64(i+1))...)
+		cell = append(cell, record...)
+		cells = append(cells, cell)
+		size += len(cell) + 2
+	}
+	if size > pageSize {
+		return nil, false
+	}
+
+	first := w.pages[0]
+	writePage(first, fileHeaderSize, leafTable, cells, 0)
+
+	header := first[:fileHeaderSize]
+	copy(header, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(header[16:], pageSize)
+	header[18], header[19] = 1, 1 // legacy journal
+	header[21], header[22], header[23] = 64, 32, 32
+	binary.BigEndian.PutUint32(header[24:], 1) // change counter
+	binary.BigEndian.PutUint32(header[28:], uint32(len(w.pages)))
+	binary.BigEndian.PutUint32(header[40:], 1) // schema cookie
+	binary.BigEndian.PutUint32(header[44:], 4) // schema format
+	binary.BigEndian.PutUint32(header[56:], 1) // UTF-8
+	binary.BigEndian.PutUint32(header[92:], 1)
+	binary.BigEndian.PutUint32(header[96:], version)
+
+	data := make([]byte, 0, len(w.pages)*pageSize)
+	for _, page := range w.pages {
+		data = append(data, page...)
+	}
+	return data, true
+}
+
+// leafCell builds a table leaf cell, moving what doesn't fit on the page to
+// overflow pages
+func (w *Writer) leafCell(rowid int64, payload []byte) tableCell {
+	cell := append(varint(uint64(len(payload))), varint(uint64(rowid))...)
+
+	usable := pageSize
+	maxLocal := usable - 35
+	if len(payload) <= maxLocal {
+		return tableCell{data: append(cell, payload...), rowid: rowid}
+	}
+
+	minLocal := (usable-12)*32/255 - 23
+	local := minLocal + (len(payload)-minLocal)%(usable-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+	cell = append(cell, payload[:local]...)
+
+	// Chain the rest through overflow pages, each starting with the next's number
+	rest := payload[local:]
+	first, page := w.allocate()
+	for {
+		n := copy(page[4:], rest)
+		rest = rest[n:]
+		if len(rest) == 0 {
+			break
+		}
+		next, nextPage := w.allocate()
+		binary.BigEndian.PutUint32(page, uint32(next))
+		page = nextPage
+	}
+
+	cell = binary.BigEndian.AppendUint32(cell, uint32(first))
+	return tableCell{data: cell, rowid: rowid}
+}
+
+// writeLeaves fills leaf pages with cells in rowid order
+func (w *Writer) writeLeaves(cells []tableCell) []treeNode {
+	nodes := make([]treeNode, 0)
+	page := make([][]byte, 0)
+	var rowid int64
+	used := 8
+
+	flush := func() {
+		number, data := w.allocate()
+		writePage(data, 0, leafTable, page, 0)
+		nodes = append(nodes, treeNode{page: number, rowid: rowid})
+		page, used = make([][]byte, 0), 8
+	}
+
+	for _, cell := range cells {
+		if used+len(cell.data)+2 > pageSize && len(page) > 0 {
+			flush()
+		}
+		page = append(page, cell.data)
+		rowid = cell.rowid
+		used += len(cell.data) + 2
+	}
+	if len(page) > 0 || len(nodes) == 0 {
+		flush()
+	}
+	return nodes
+}
+
+// writeInteriors writes a level of interior pages over nodes, splitting them
+// evenly so no page is left with a single child
+func (w *Writer) writeInteriors(nodes []treeNode) []treeNode {
+	// A cell is a 4 byte page number and a rowid of up to 9 bytes, plus its pointer
+	perPage := (pageSize - 12) / 15
+	count := (len(nodes) + perPage - 1) / perPage
+
+	total := len(nodes)
+	parents := make([]treeNode, 0, count)
+	for i := 0; i < count; i++ {
+		size := total / count
+		if i < total%count {
+			size++
+		}
+		children := nodes[:size]
+		nodes = nodes[size:]
+
+		cells := make([][]byte, 0, len(children)-1)
+		for _, child := range children[:len(children)-1] {
+			cell := binary.BigEndian.AppendUint32(nil, uint32(child.page))
+			cells = append(cells, append(cell, varint(uint64(child.rowid))...))
+		}
+
+		last := children[len(children)-1]
+		number, data := w.allocate()
+		writePage(data, 0, interiorTable, cells, last.page)
+		parents = append(parents, treeNode{page: number, rowid: last.rowid})
+	}
+	return parents
+}
+
+// writePage lays out a b-tree page whose header starts at offset: the
+// header, the cell pointers, then the cells packed at the end of the page
+func writePage(page []byte, offset int, pageType byte, cells [][]byte, rightChild int) {
+	headerSize := 8
+	page[offset] = pageType
+	if pageType == interiorTable {
+		headerSize = 12
+		binary.BigEndian.PutUint32(page[offset+8:], uint32(rightChild))
+	}
+
+	content := len(page)
+	for i, cell := range cells {
+		content -= len(cell)
+		copy(page[content:], cell)
+		binary.BigEndian.PutUint16(page[offset+headerSize+2*i:], uint16(content))
+	}
+	binary.BigEndian.PutUint16(page[offset+3:], uint16(len(cells)))
+	binary.BigEndian.PutUint16(page[offset+5:], uint16(content))
+}
+
+// encodeRecord encodes a row of strings, int64s, float64s and nils
+func encodeRecord(values []interface{}) []byte {
+	types := make([]byte, 0, len(values))
+	body := make([]byte, 0)
+
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+			types = append(types, 0)
+		case int64:
+			serialType, size := intType(v)
+			types = append(types, varint(serialType)...)
+			for i := size - 1; i >= 0; i-- {
+				body = append(body, byte(v>>(8*i)))
+			}
+		case float64:
+			types = append(types, 7)
+			body = binary.BigEndian.AppendUint64(body, math.Float64bits(v))
+		case string:
+			types = append(types, varint(uint64(2*len(v)+13))...)
+			body = append(body, v...)
+		}
+	}
+
+	// The header's size includes the varint giving it
+	headerSize := len(types) + 1
+	if len(varint(uint64(headerSize))) > 1 {
+		headerSize = len(types) + len(varint(uint64(len(types)+2)))
+	}
+
+	record := append(varint(uint64(headerSize)), types...)
+	return append(record, body...)
+}
+
+// intType returns the serial type of an integer and its size in bytes
+func intType(v int64) (uint64, int) {
+	switch {
+	case v == 0:
+		return 8, 0
+	case v == 1:
+		return 9, 0
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		return 1, 1
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		return 2, 2
+	case v >= -1<<23 && v < 1<<23:
+		return 3, 3
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		return 4, 4
+	case v >= -1<<47 && v < 1<<47:
+		return 5, 6
+	}
+	return 6, 8
+}
+
+// varint encodes a SQLite variable-length integer: big-endian groups of
+// 7 bits, with a ninth byte holding a full 8 bits
+func varint(v uint64) []byte {
+	if v > 0x00ffffffffffffff {
+		b := make([]byte, 9)
+		b[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			b[i] = byte(v&0x7f) | 0x80
+			v >>= 7
+		}
+		return b
+	}
+
+	groups := make([]byte, 0, 8)
+	for {
+		groups = append(groups, byte(v&0x7f))
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+
+	b := make([]byte, len(groups))
+	for i := range groups {
+		b[i] = groups[len(groups)-1-i] | 0x80
+	}
+	b[len(b)-1] &= 0x7f
+	return b
+}
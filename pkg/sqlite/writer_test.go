@@ -0,0 +1,184 @@
+package sqlite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// writeDatabase writes a database file for the tables added by build
+func writeDatabase(t *testing.T, build func(w *Writer)) string {
+	t.Helper()
+
+	w := NewWriter()
+	build(w)
+	data, ok := w.Bytes()
+	if !ok {
+		t.Fatal("schema doesn't fit on the first page")
+	}
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// query runs SQL on a database file with the sqlite3 command, skipping the
+// test when it isn't installed, and returns the rows as JSON objects
+func query(t *testing.T, path string, sql string) []map[string]interface{} {
+	t.Helper()
+
+	sqlite3, err := exec.LookPath("sqlite3")
+	if err != nil {
+		t.Skip("sqlite3 isn't installed")
+	}
+	out, err := exec.Command(sqlite3, "-json", "-bail", path, sql).CombinedOutput()
+	if err != nil {
+		t.Fatalf("sqlite3 %q: %v: %s", sql, err, out)
+	}
+
+	rows := make([]map[string]interface{}, 0)
+	if len(bytes.TrimSpace(out)) == 0 {
+		return rows
+	}
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	decoder.UseNumber()
+	if err := decoder.Decode(&rows); err != nil {
+		t.Fatalf("sqlite3 output %q: %v", out, err)
+	}
+	return rows
+}
+
+// checkIntegrity runs SQLite's own check of the file's structure
+func checkIntegrity(t *testing.T, path string) {
+	t.Helper()
+
+	rows := query(t, path, "PRAGMA integrity_check")
+	if len(rows) != 1 || rows[0]["integrity_check"] != "ok" {
+		t.Fatalf("integrity_check = %v", rows)
+	}
+}
+
+// sameValue reports whether a value read back by sqlite3 is the one written
+func sameValue(written interface{}, read interface{}) bool {
+	switch v := written.(type) {
+	case nil:
+		return read == nil
+	case int64:
+		return read == json.Number(strconv.FormatInt(v, 10))
+	case float64:
+		number, ok := read.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := strconv.ParseFloat(string(number), 64)
+		return err == nil && f == v
+	case string:
+		return read == v
+	}
+	return false
+}
+
+func TestWriterValues(t *testing.T) {
+	// Integers at the edges of every serial type's size, and text either side
+	// of the page's local payload limit
+	ints := []int64{0, 1, 2, -1, math.MaxInt8, math.MinInt8, math.MaxInt8 + 1, math.MaxInt16, math.MinInt16,
+		math.MaxInt16 + 1, 1<<23 - 1, -1 << 23, 1 << 23, math.MaxInt32, math.MinInt32, math.MaxInt32 + 1,
+		1<<47 - 1, -1 << 47, 1 << 47, math.MaxInt64, math.MinInt64}
+	texts := []string{"", "a", "pizza ☺", strings.Repeat("x", pageSize-36), strings.Repeat("y", pageSize-35),
+		strings.Repeat("z", pageSize-34), strings.Repeat("long ", 2000), strings.Repeat("much longer ", 10000)}
+	floats := []float64{0.5, -13.99, 1e100, math.SmallestNonzeroFloat64}
+
+	rows := make([][]interface{}, 0)
+	for i := 0; i < len(ints) || i < len(texts) || i < len(floats); i++ {
+		row := []interface{}{nil, nil, nil}
+		if i < len(ints) {
+			row[0] = ints[i]
+		}
+		if i < len(floats) {
+			row[1] = floats[i]
+		}
+		if i < len(texts) {
+			row[2] = texts[i]
+		}
+		rows = append(rows, row)
+	}
+
+	path := writeDatabase(t, func(w *Writer) {
+		w.AddTable("vals", "CREATE TABLE vals (i INTEGER, r REAL, t TEXT)", rows)
+	})
+	checkIntegrity(t, path)
+
+	got := query(t, path, "SELECT rowid, i, r, t FROM vals ORDER BY rowid")
+	if len(got) != len(rows) {
+		t.Fatalf("read %d rows, want %d", len(got), len(rows))
+	}
+	for n, row := range rows {
+		if got[n]["rowid"] != json.Number(strconv.Itoa(n+1)) {
+			t.Errorf("row %d has rowid %v", n+1, got[n]["rowid"])
+		}
+		for i, column := range []string{"i", "r", "t"} {
+			if !sameValue(row[i], got[n][column]) {
+				t.Errorf("row %d %s = %.40v, want %.40v", n+1, column, got[n][column], row[i])
+			}
+		}
+	}
+}
+
+func TestWriterTables(t *testing.T) {
+	// Enough rows for two levels of interior pages
+	const count = 40000
+	many := make([][]interface{}, count)
+	for i := range many {
+		many[i] = []interface{}{int64(i), fmt.Sprintf("row %d of a table with more leaf pages than fit under one", i)}
+	}
+
+	path := writeDatabase(t, func(w *Writer) {
+		w.AddTable("empty", "CREATE TABLE empty (a TEXT)", nil)
+		w.AddTable("many", "CREATE TABLE many (n INTEGER, label TEXT)", many)
+		w.AddTable("one", "CREATE TABLE one (a TEXT, b INTEGER)", [][]interface{}{{"only", int64(7)}})
+	})
+	checkIntegrity(t, path)
+
+	schema := query(t, path, "SELECT name, sql FROM sqlite_master ORDER BY rowid")
+	if len(schema) != 3 || schema[0]["name"] != "empty" || schema[1]["sql"] != "CREATE TABLE many (n INTEGER, label TEXT)" || schema[2]["name"] != "one" {
+		t.Errorf("sqlite_master = %v", schema)
+	}
+
+	if rows := query(t, path, "SELECT count(*) AS n FROM empty"); rows[0]["n"] != json.Number("0") {
+		t.Errorf("empty has %v rows", rows[0]["n"])
+	}
+
+	rows := query(t, path, "SELECT count(*) AS n, sum(n) AS total, max(rowid) AS last FROM many")
+	if rows[0]["n"] != json.Number(strconv.Itoa(count)) || rows[0]["total"] != json.Number(strconv.Itoa(count*(count-1)/2)) || rows[0]["last"] != json.Number(strconv.Itoa(count)) {
+		t.Errorf("many = %v, want %d rows", rows[0], count)
+	}
+
+	// Lookups by rowid walk the interior pages rather than scanning
+	rows = query(t, path, "SELECT label FROM many WHERE rowid = 31337")
+	if len(rows) != 1 || !strings.HasPrefix(fmt.Sprint(rows[0]["label"]), "row 31336 ") {
+		t.Errorf("rowid 31337 = %v", rows)
+	}
+
+	if rows := query(t, path, "SELECT a, b FROM one"); len(rows) != 1 || rows[0]["a"] != "only" || rows[0]["b"] != json.Number("7") {
+		t.Errorf("one = %v", rows)
+	}
+}
+
+func TestWriterSchemaTooLarge(t *testing.T) {
+	w := NewWriter()
+	for i := 0; i < 10; i++ {
+		w.AddTable(fmt.Sprintf("t%d", i), "CREATE TABLE t (a TEXT) -- "+strings.Repeat("x", pageSize/8), nil)
+	}
+	if _, ok := w.Bytes(); ok {
+		t.Error("Bytes wrote a schema too large for the first page")
+	}
+}
@@ -2,7 +2,9 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/xml"
 	"io"
 	"net/http"
 	"time"
@@ -15,6 +17,11 @@ var Client = &http.Client{
 
 // Post sends a POST request with JSON payload to the specified URL
 func Post(url string, payload interface{}) (map[string]interface{}, error) {
+	return PostContext(context.Background(), url, payload)
+}
+
+// PostContext is like Post but aborts the request when ctx is done
+func PostContext(ctx context.Context, url string, payload interface{}) (map[string]interface{}, error) {
 	// Convert payload to JSON
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
@@ -22,7 +29,7 @@ func Post(url string, payload interface{}) (map[string]interface{}, error) {
 	}
 
 	// Create request
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, err
 	}
@@ -90,14 +97,89 @@ func Get(url string) (map[string]interface{}, error) {
 	return result, nil
 }
 
-// GetTracking sends a specialized GET request for tracking orders
+// GetTracking sends a specialized GET request for tracking orders. An empty
+// market uses the market of the active configuration.
 func GetTracking(url string, market string) (map[string]interface{}, error) {
+	return GetTrackingContext(context.Background(), url, market, "")
+}
+
+// GetTrackingContext is like GetTracking but aborts the request when ctx is done
+// and takes the language to track in (the configured language if empty).
+// A JSON array response is returned under the "Orders" key.
+func GetTrackingContext(ctx context.Context, url string, market string, language string) (map[string]interface{}, error) {
+	body, err := getTrackingBody(ctx, url, market, language)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse JSON response; lookups by phone return a list of orders
+	var result interface{}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if orders, ok := result.([]interface{}); ok {
+		return map[string]interface{}{"Orders": orders}, nil
+	}
+
+	resultMap, _ := result.(map[string]interface{})
+	return resultMap, nil
+}
+
+// trackerDataEnvelope is the SOAP response of the legacy GetTrackerData endpoint
+type trackerDataEnvelope struct {
+	OrderStatuses []struct {
+		Fields []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"Body>GetTrackerDataResponse>OrderStatuses>OrderStatus"`
+}
+
+// GetTrackerData queries the legacy SOAP tracker used by OrderStorageTracker markets.
+// Each order's fields are returned as strings under the "Orders" key.
+func GetTrackerData(ctx context.Context, url string, market string, language string) (map[string]interface{}, error) {
+	body, err := getTrackingBody(ctx, url, market, language)
+	if err != nil {
+		return nil, err
+	}
+
+	var envelope trackerDataEnvelope
+	if err := xml.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+
+	orders := make([]interface{}, 0, len(envelope.OrderStatuses))
+	for _, status := range envelope.OrderStatuses {
+		order := make(map[string]interface{})
+		for _, field := range status.Fields {
+			order[field.XMLName.Local] = field.Value
+		}
+		orders = append(orders, order)
+	}
+
+	return map[string]interface{}{"Orders": orders}, nil
+}
+
+// getTrackingBody sends a tracking request with the dpz market and language headers
+func getTrackingBody(ctx context.Context, url string, market string, language string) ([]byte, error) {
+	if market == "" {
+		market = URLs.Market
+	}
 	if market == "" {
 		market = "UNITED_STATES"
 	}
 
+	if language == "" {
+		language = CurrentLanguage()
+	}
+	if language == "" {
+		language = "en"
+	}
+
 	// Create request
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -105,7 +187,7 @@ func GetTracking(url string, market string) (map[string]interface{}, error) {
 	// Set headers
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
-	req.Header.Set("dpz-language", "en")
+	req.Header.Set("dpz-language", language)
 	req.Header.Set("dpz-market", market)
 
 	// Send request
@@ -116,17 +198,5 @@ func GetTracking(url string, market string) (map[string]interface{}, error) {
 	defer resp.Body.Close()
 
 	// Read response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	// Parse JSON response
-	var result map[string]interface{}
-	err = json.Unmarshal(body, &result)
-	if err != nil {
-		return nil, err
-	}
-
-	return result, nil
+	return io.ReadAll(resp.Body)
 }
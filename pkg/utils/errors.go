@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"log/slog"
 )
 
 // DominosError is the base error type for all Domino's API errors
@@ -10,8 +11,28 @@ type DominosError struct {
 	Details interface{}
 }
 
+// Error describes the error with card data, phone numbers and emails in the
+// details redacted, unless ShowSensitiveData is on
 func (e *DominosError) Error() string {
-	return fmt.Sprintf("%s: %v", e.Message, e.Details)
+	return fmt.Sprintf("%s: %v", e.Message, RedactValue(e.Details))
+}
+
+// Format writes the redacted Error text for every verb, so %+v and %#v can't
+// dump the raw details
+func (e *DominosError) Format(f fmt.State, verb rune) {
+	if verb == 'q' {
+		fmt.Fprintf(f, "%q", e.Error())
+		return
+	}
+	fmt.Fprint(f, e.Error())
+}
+
+// LogValue logs the message and the redacted details
+func (e *DominosError) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("message", e.Message),
+		slog.Any("details", RedactValue(e.Details)),
+	)
 }
 
 // DominosValidationError represents an error during order validation
@@ -133,3 +154,79 @@ func NewDominosProductsError(details interface{}) *DominosProductsError {
 		},
 	}
 }
+
+// DominosMarketError represents an error with a market configuration
+type DominosMarketError struct {
+	DominosError
+}
+
+// NewDominosMarketError creates a new market error
+func NewDominosMarketError(details interface{}) *DominosMarketError {
+	return &DominosMarketError{
+		DominosError: DominosError{
+			Message: "Market error",
+			Details: details,
+		},
+	}
+}
+
+// DominosCurrencyError represents an error with a monetary amount or currency
+type DominosCurrencyError struct {
+	DominosError
+}
+
+// NewDominosCurrencyError creates a new currency error
+func NewDominosCurrencyError(details interface{}) *DominosCurrencyError {
+	return &DominosCurrencyError{
+		DominosError: DominosError{
+			Message: "Currency error",
+			Details: details,
+		},
+	}
+}
+
+// DominosPaymentError represents an invalid or unaccepted payment
+type DominosPaymentError struct {
+	DominosError
+}
+
+// NewDominosPaymentError creates a new payment error
+func NewDominosPaymentError(details interface{}) *DominosPaymentError {
+	return &DominosPaymentError{
+		DominosError: DominosError{
+			Message: "Payment error",
+			Details: details,
+		},
+	}
+}
+
+// DominosCardError represents a credit card that failed validation. Details
+// holds one of the card validation reasons, such as "card is expired".
+type DominosCardError struct {
+	DominosError
+}
+
+// NewDominosCardError creates a new card validation error
+func NewDominosCardError(details interface{}) *DominosCardError {
+	return &DominosCardError{
+		DominosError: DominosError{
+			Message: "Card validation failed",
+			Details: details,
+		},
+	}
+}
+
+// DominosCouponError represents a coupon that is unknown or that the order doesn't satisfy
+type DominosCouponError struct {
+	DominosError
+}
+
+// NewDominosCouponError creates a new coupon error
+func NewDominosCouponError(details interface{}) *DominosCouponError {
+	return &DominosCouponError{
+		DominosError: DominosError{
+			Message: "Coupon error",
+			Details: details,
+		},
+	}
+}
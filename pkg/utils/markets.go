@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TrackingStrategy describes how a market's order tracker is queried
+type TrackingStrategy string
+
+const (
+	// TrackerPresentationService is the JSON tracker used in the USA
+	TrackerPresentationService TrackingStrategy = "tracker-presentation-service"
+	// OrderStorageTracker is the legacy SOAP GetTrackerData endpoint used in Canada
+	OrderStorageTracker TrackingStrategy = "orderstorage"
+)
+
+// Market describes everything that differs between Domino's markets
+type Market struct {
+	// Code is the short registry key, e.g. "US"
+	Code            string
+	Endpoints       URLConfig
+	Tracking        TrackingStrategy
+	Currency        string // ISO 4217 code
+	DefaultLanguage string
+	Languages       []string
+	// PhonePattern and PostalCodePattern match sanitized customer input
+	PhonePattern      *regexp.Regexp
+	PostalCodePattern *regexp.Regexp
+}
+
+// ValidatePhone reports whether phone, ignoring anything but digits, is valid in this market
+func (m *Market) ValidatePhone(phone string) bool {
+	if m.PhonePattern == nil {
+		return true
+	}
+
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+
+	return m.PhonePattern.MatchString(digits)
+}
+
+// ValidatePostalCode reports whether postalCode is valid in this market
+func (m *Market) ValidatePostalCode(postalCode string) bool {
+	if m.PostalCodePattern == nil {
+		return true
+	}
+	return m.PostalCodePattern.MatchString(strings.TrimSpace(postalCode))
+}
+
+// Built-in markets
+var (
+	USMarket = &Market{
+		Code:              "US",
+		Endpoints:         USA,
+		Tracking:          TrackerPresentationService,
+		Currency:          "USD",
+		DefaultLanguage:   "en",
+		Languages:         []string{"en", "es"},
+		PhonePattern:      regexp.MustCompile(`^1?\d{10}$`),
+		PostalCodePattern: regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+	}
+
+	CanadaMarket = &Market{
+		Code:              "CA",
+		Endpoints:         Canada,
+		Tracking:          OrderStorageTracker,
+		Currency:          "CAD",
+		DefaultLanguage:   "en",
+		Languages:         []string{"en", "fr"},
+		PhonePattern:      regexp.MustCompile(`^1?\d{10}$`),
+		PostalCodePattern: regexp.MustCompile(`^[A-Za-z]\d[A-Za-z][ -]?\d[A-Za-z]\d$`),
+	}
+)
+
+var (
+	marketsMu sync.RWMutex
+	// markets are kept in the order they were registered, so CurrentMarket
+	// picks the same one every time when two share a dpz market
+	markets = []*Market{USMarket, CanadaMarket}
+	// active is the market chosen with UseMarket
+	active *Market
+)
+
+// RegisterMarket adds a market to the registry, or replaces the market with the same code
+func RegisterMarket(market *Market) error {
+	if market == nil || market.Code == "" {
+		return NewDominosMarketError("Market code is required to register a market")
+	}
+	if market.Endpoints.Market == "" {
+		return NewDominosMarketError("Market endpoints must name the dpz market")
+	}
+
+	marketsMu.Lock()
+	defer marketsMu.Unlock()
+
+	for i, registered := range markets {
+		if strings.EqualFold(registered.Code, market.Code) {
+			markets[i] = market
+			return nil
+		}
+	}
+	markets = append(markets, market)
+	return nil
+}
+
+// LookupMarket finds a registered market by code
+func LookupMarket(code string) (*Market, bool) {
+	marketsMu.RLock()
+	defer marketsMu.RUnlock()
+
+	for _, market := range markets {
+		if strings.EqualFold(market.Code, code) {
+			return market, true
+		}
+	}
+	return nil, false
+}
+
+// Markets returns every registered market sorted by code
+func Markets() []*Market {
+	marketsMu.RLock()
+	defer marketsMu.RUnlock()
+
+	result := append([]*Market(nil), markets...)
+	sort.Slice(result, func(i, j int) bool { return result[i].Code < result[j].Code })
+
+	return result
+}
+
+// UseMarket switches the active configuration to a registered market
+func UseMarket(code string) error {
+	market, ok := LookupMarket(code)
+	if !ok {
+		return NewDominosMarketError("Unknown market " + code)
+	}
+
+	config := market.Endpoints
+	if market.DefaultLanguage != "" {
+		config.Language = market.DefaultLanguage
+	}
+	UseInternational(config)
+
+	marketsMu.Lock()
+	active = market
+	marketsMu.Unlock()
+
+	return nil
+}
+
+// CurrentMarket returns the registered market matching the active configuration:
+// the one chosen with UseMarket, or else the first registered with the
+// configuration's dpz market. Configurations that
+// aren't registered get a market without a Code or Currency, so amounts are
+// left unlabelled rather than assumed to be dollars.
+func CurrentMarket() *Market {
+	marketsMu.RLock()
+	defer marketsMu.RUnlock()
+
+	if active != nil && active.Endpoints.Market == URLs.Market {
+		return active
+	}
+	for _, market := range markets {
+		if market.Endpoints.Market == URLs.Market {
+			return market
+		}
+	}
+
+	return &Market{
+		Endpoints:       URLs,
+		Tracking:        TrackerPresentationService,
+		DefaultLanguage: URLs.Language,
+	}
+}
@@ -0,0 +1,75 @@
+package utils
+
+import "testing"
+
+// restoreMarkets puts the registry and active configuration back after a test
+func restoreMarkets(t *testing.T) {
+	t.Helper()
+
+	savedURLs, savedMarkets, savedActive := URLs, append([]*Market(nil), markets...), active
+	t.Cleanup(func() {
+		URLs, markets, active = savedURLs, savedMarkets, savedActive
+	})
+}
+
+func TestCurrentMarketSharedDpzMarket(t *testing.T) {
+	restoreMarkets(t)
+
+	endpoints := USA
+	endpoints.Language = "es"
+	if err := RegisterMarket(&Market{Code: "US-ES", Endpoints: endpoints, Currency: "USD"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without UseMarket, the first registered market wins every time
+	UseInternational(USA)
+	for i := 0; i < 20; i++ {
+		if code := CurrentMarket().Code; code != "US" {
+			t.Fatalf("CurrentMarket() = %s, want US", code)
+		}
+	}
+
+	if err := UseMarket("us-es"); err != nil {
+		t.Fatal(err)
+	}
+	if code := CurrentMarket().Code; code != "US-ES" {
+		t.Errorf("CurrentMarket() after UseMarket = %s, want US-ES", code)
+	}
+
+	UseInternational(Canada)
+	if code := CurrentMarket().Code; code != "CA" {
+		t.Errorf("CurrentMarket() after switching to Canada = %s, want CA", code)
+	}
+}
+
+func TestCurrentMarketUnregistered(t *testing.T) {
+	restoreMarkets(t)
+
+	config := USA
+	config.Market = "ATLANTIS"
+	UseInternational(config)
+
+	market := CurrentMarket()
+	if market.Code != "" || market.Currency != "" {
+		t.Errorf("CurrentMarket() = %+v, want no code or currency", market)
+	}
+}
+
+func TestRegisterMarketReplaces(t *testing.T) {
+	restoreMarkets(t)
+
+	if err := RegisterMarket(&Market{Code: "us", Endpoints: USA, Currency: "XXX"}); err != nil {
+		t.Fatal(err)
+	}
+	market, ok := LookupMarket("US")
+	if !ok || market.Currency != "XXX" {
+		t.Errorf("LookupMarket(US) = %+v, %v, want the replacement", market, ok)
+	}
+	if len(Markets()) != 2 {
+		t.Errorf("Markets() = %d markets, want 2", len(Markets()))
+	}
+
+	if err := RegisterMarket(&Market{Code: "XX"}); err == nil {
+		t.Error("registering a market without a dpz market succeeded")
+	}
+}
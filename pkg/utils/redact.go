@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// Mask written in place of redacted values
+const redactedMask = "[REDACTED]"
+
+// Keys whose values are redacted from API responses and payloads, lowercased
+var sensitiveKeys = map[string]bool{
+	"number":             true,
+	"cardnumber":         true,
+	"securitycode":       true,
+	"cvv":                true,
+	"pin":                true,
+	"expiration":         true,
+	"cardid":             true,
+	"paymentmethodnonce": true,
+	"phone":              true,
+	"phonenumber":        true,
+	"email":              true,
+}
+
+var showSensitiveData atomic.Bool
+
+// ShowSensitiveData opts in to printing and logging card data, phone numbers and
+// email addresses unredacted. It is meant for local debugging only.
+func ShowSensitiveData(show bool) {
+	showSensitiveData.Store(show)
+}
+
+// SensitiveDataShown reports whether redaction has been turned off with ShowSensitiveData
+func SensitiveDataShown() bool {
+	return showSensitiveData.Load()
+}
+
+// RedactCardNumber masks all but the last four digits of a card number
+func RedactCardNumber(number string) string {
+	return redactKeepingLast(number, 4)
+}
+
+// RedactPhone masks all but the last four digits of a phone number
+func RedactPhone(phone string) string {
+	return redactKeepingLast(phone, 4)
+}
+
+// RedactEmail masks the local part of an email address after its first character
+func RedactEmail(email string) string {
+	if SensitiveDataShown() || email == "" {
+		return email
+	}
+
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return redactedMask
+	}
+	return local[:1] + "***@" + domain
+}
+
+// RedactSecret masks a value entirely, such as a security code or PIN
+func RedactSecret(secret string) string {
+	if SensitiveDataShown() || secret == "" {
+		return secret
+	}
+	return redactedMask
+}
+
+// RedactValue returns a copy of a decoded API response or payload with the values
+// of sensitive keys masked. Values of other types are returned as is.
+func RedactValue(value interface{}) interface{} {
+	if SensitiveDataShown() {
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if sensitiveKeys[strings.ToLower(key)] {
+				redacted[key] = redactScalar(key, item)
+			} else {
+				redacted[key] = RedactValue(item)
+			}
+		}
+		return redacted
+
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = RedactValue(item)
+		}
+		return redacted
+
+	case []map[string]interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = RedactValue(item)
+		}
+		return redacted
+	}
+
+	return value
+}
+
+// redactScalar masks the value of a sensitive key
+func redactScalar(key string, value interface{}) interface{} {
+	text, ok := value.(string)
+	if !ok {
+		if value == nil {
+			return nil
+		}
+		return redactedMask
+	}
+
+	switch strings.ToLower(key) {
+	case "number", "cardnumber":
+		return RedactCardNumber(text)
+	case "phone", "phonenumber":
+		return RedactPhone(text)
+	case "email":
+		return RedactEmail(text)
+	}
+	return RedactSecret(text)
+}
+
+// redactKeepingLast masks every character of value except the last n
+func redactKeepingLast(value string, n int) string {
+	if SensitiveDataShown() || value == "" {
+		return value
+	}
+	if len(value) <= n {
+		return strings.Repeat("*", len(value))
+	}
+	return strings.Repeat("*", len(value)-n) + value[len(value)-n:]
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// showSensitiveDataFor turns redaction off for a test
+func showSensitiveDataFor(t *testing.T) {
+	t.Helper()
+
+	ShowSensitiveData(true)
+	t.Cleanup(func() { ShowSensitiveData(false) })
+}
+
+func TestRedactHelpers(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"card number", RedactCardNumber("4111111111111111"), "************1111"},
+		{"short card number", RedactCardNumber("411"), "***"},
+		{"phone", RedactPhone("5555551234"), "******1234"},
+		{"email", RedactEmail("jane@example.com"), "j***@example.com"},
+		{"email without local part", RedactEmail("@example.com"), "[REDACTED]"},
+		{"secret", RedactSecret("123"), "[REDACTED]"},
+		{"empty secret", RedactSecret(""), ""},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestRedactValue(t *testing.T) {
+	response := map[string]interface{}{
+		"Order": map[string]interface{}{
+			"Email": "jane@example.com",
+			"Payments": []interface{}{
+				map[string]interface{}{"Number": "4111111111111111", "SecurityCode": "123", "Amount": 10.5},
+			},
+		},
+		"Status": 1.0,
+	}
+
+	redacted := RedactValue(response).(map[string]interface{})
+	order := redacted["Order"].(map[string]interface{})
+	payment := order["Payments"].([]interface{})[0].(map[string]interface{})
+
+	if order["Email"] != "j***@example.com" || payment["Number"] != "************1111" || payment["SecurityCode"] != "[REDACTED]" {
+		t.Errorf("RedactValue = %v", redacted)
+	}
+	if payment["Amount"] != 10.5 || redacted["Status"] != 1.0 {
+		t.Errorf("RedactValue changed values that aren't sensitive: %v", redacted)
+	}
+
+	original := response["Order"].(map[string]interface{})["Payments"].([]interface{})[0].(map[string]interface{})
+	if original["Number"] != "4111111111111111" {
+		t.Error("RedactValue modified the response it was given")
+	}
+}
+
+func TestDominosErrorRedaction(t *testing.T) {
+	err := NewDominosPlaceOrderError(map[string]interface{}{
+		"Order": map[string]interface{}{
+			"Phone":    "5555551234",
+			"Payments": []interface{}{map[string]interface{}{"Number": "4111111111111111"}},
+		},
+	})
+
+	outputs := map[string]string{
+		"Error": err.Error(),
+		"%v":    fmt.Sprintf("%v", err),
+		"%+v":   fmt.Sprintf("%+v", err),
+		"%#v":   fmt.Sprintf("%#v", err),
+		"%s":    fmt.Sprintf("%s", err),
+		"%q":    fmt.Sprintf("%q", err),
+	}
+	for _, format := range []string{"json", "text"} {
+		var buf bytes.Buffer
+		var handler slog.Handler = slog.NewJSONHandler(&buf, nil)
+		if format == "text" {
+			handler = slog.NewTextHandler(&buf, nil)
+		}
+		slog.New(handler).Error("place failed", "err", err)
+		outputs["slog "+format] = buf.String()
+	}
+
+	for name, output := range outputs {
+		if strings.Contains(output, "4111111111111111") || strings.Contains(output, "5555551234") {
+			t.Errorf("%s leaks sensitive data: %s", name, output)
+		}
+		if !strings.Contains(output, "1111") {
+			t.Errorf("%s = %s, want the card's last four digits", name, output)
+		}
+	}
+
+	showSensitiveDataFor(t)
+	if output := fmt.Sprintf("%+v", err); !strings.Contains(output, "4111111111111111") {
+		t.Errorf("with ShowSensitiveData, %%+v = %s", output)
+	}
+}
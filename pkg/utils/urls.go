@@ -3,13 +3,18 @@ package utils
 // URLConfig represents a set of Domino's API endpoints for a specific country
 type URLConfig struct {
 	SourceURI string
-	Location  struct {
+	// Market is sent as the dpz-market tracking header and on orders
+	Market string
+	// Language is used for menus, orders and tracking unless overridden
+	Language string
+	Location struct {
 		Find string
 	}
 	Store struct {
-		Find string
-		Info string
-		Menu string
+		Find   string
+		Info   string
+		Menu   string
+		Coupon string
 	}
 	Order struct {
 		Validate string
@@ -19,27 +24,40 @@ type URLConfig struct {
 	Images     string
 	TrackRoot  string
 	Track      string
+	Tracker    TrackerURLs
 	Token      string
 	Upsell     string
 	StepUpsell string
 }
 
+// TrackerURLs are the tracker lookups of a market. An empty template means the
+// market doesn't support that lookup.
+type TrackerURLs struct {
+	Phone     string
+	OrderKey  string
+	PulseGUID string
+}
+
 // USA Domino's Pizza API URLs
 var USA = URLConfig{
 	SourceURI: "order.dominos.com",
+	Market:    "UNITED_STATES",
+	Language:  "en",
 	Location: struct {
 		Find string
 	}{
 		Find: "https://api.dominos.com/store-locator-international-service/findAddress?latitude=${lat}&longitude=${lon}",
 	},
 	Store: struct {
-		Find string
-		Info string
-		Menu string
+		Find   string
+		Info   string
+		Menu   string
+		Coupon string
 	}{
-		Find: "https://order.dominos.com/power/store-locator?s=${line1}&c=${line2}&type=${pickUpType}",
-		Info: "https://order.dominos.com/power/store/${storeID}/profile",
-		Menu: "https://order.dominos.com/power/store/${storeID}/menu?lang=${lang}&structured=true",
+		Find:   "https://order.dominos.com/power/store-locator?s=${line1}&c=${line2}&type=${pickUpType}",
+		Info:   "https://order.dominos.com/power/store/${storeID}/profile",
+		Menu:   "https://order.dominos.com/power/store/${storeID}/menu?lang=${lang}&structured=true",
+		Coupon: "https://order.dominos.com/power/store/${storeID}/coupon/${couponCode}?lang=${lang}",
 	},
 	Order: struct {
 		Validate string
@@ -50,30 +68,39 @@ var USA = URLConfig{
 		Price:    "https://order.dominos.com/power/price-order",
 		Place:    "https://order.dominos.com/power/place-order",
 	},
-	Images:     "https://cache.dominos.com/olo/6_47_2/assets/build/market/US/_en/images/img/products/larges/${productCode}.jpg",
-	TrackRoot:  "https://tracker.dominos.com/tracker-presentation-service/",
-	Track:      "v2/orders",
+	Images:    "https://cache.dominos.com/olo/6_47_2/assets/build/market/US/_en/images/img/products/larges/${productCode}.jpg",
+	TrackRoot: "https://tracker.dominos.com/tracker-presentation-service/",
+	Track:     "v2/orders",
+	Tracker: TrackerURLs{
+		Phone:     "https://tracker.dominos.com/tracker-presentation-service/v2/orders?phonenumber=${phone}",
+		OrderKey:  "https://tracker.dominos.com/tracker-presentation-service/v2/orders/stores/${storeID}/orders/${orderKey}",
+		PulseGUID: "https://tracker.dominos.com/tracker-presentation-service/v2/orders/${pulseOrderGUID}",
+	},
 	Token:      "https://order.dominos.com/power/paymentGatewayService/braintree/token",
-	Upsell:     "https://api.dominos.com/upsell-service/stores/upsellForOrder/",
-	StepUpsell: "https://api.dominos.com/upsell-service/stores/stepUpsellForOrder",
+	Upsell:     "https://api.dominos.com/upsell-service/stores/upsellForOrder/${storeID}",
+	StepUpsell: "https://api.dominos.com/upsell-service/stores/stepUpsellForOrder/${storeID}",
 }
 
 // Canada Domino's Pizza API URLs
 var Canada = URLConfig{
 	SourceURI: "order.dominos.ca",
+	Market:    "CANADA",
+	Language:  "en",
 	Location: struct {
 		Find string
 	}{
 		Find: "https://api.dominos.com/store-locator-international-service/findAddress?latitude=${lat}&longitude=${lon}",
 	},
 	Store: struct {
-		Find string
-		Info string
-		Menu string
+		Find   string
+		Info   string
+		Menu   string
+		Coupon string
 	}{
-		Find: "https://order.dominos.ca/power/store-locator?s=${line1}&c=${line2}&type=${type}",
-		Info: "https://order.dominos.ca/power/store/${storeID}/profile",
-		Menu: "https://order.dominos.ca/power/store/${storeID}/menu?lang=${lang}&structured=true",
+		Find:   "https://order.dominos.ca/power/store-locator?s=${line1}&c=${line2}&type=${pickUpType}",
+		Info:   "https://order.dominos.ca/power/store/${storeID}/profile",
+		Menu:   "https://order.dominos.ca/power/store/${storeID}/menu?lang=${lang}&structured=true",
+		Coupon: "https://order.dominos.ca/power/store/${storeID}/coupon/${couponCode}?lang=${lang}",
 	},
 	Order: struct {
 		Validate string
@@ -84,11 +111,15 @@ var Canada = URLConfig{
 		Price:    "https://order.dominos.ca/power/price-order",
 		Place:    "https://order.dominos.ca/power/place-order",
 	},
-	Images:     "https://cache.dominos.com/nolo/ca/en/6_44_3/assets/build/market/CA/_en/images/img/products/larges/${itemCode}.jpg",
-	Track:      "https://order.dominos.ca/orderstorage/GetTrackerData?",
+	Images: "https://cache.dominos.com/nolo/ca/en/6_44_3/assets/build/market/CA/_en/images/img/products/larges/${itemCode}.jpg",
+	Track:  "https://order.dominos.ca/orderstorage/GetTrackerData?",
+	Tracker: TrackerURLs{
+		Phone:    "https://order.dominos.ca/orderstorage/GetTrackerData?Phone=${phone}",
+		OrderKey: "https://order.dominos.ca/orderstorage/GetTrackerData?StoreID=${storeID}&OrderKey=${orderKey}",
+	},
 	Token:      "https://order.dominos.com/power/paymentGatewayService/braintree/token",
-	Upsell:     "https://api.dominos.com/upsell-service/stores/upsellForOrder/",
-	StepUpsell: "https://api.dominos.com/upsell-service/stores/stepUpsellForOrder",
+	Upsell:     "https://api.dominos.com/upsell-service/stores/upsellForOrder/${storeID}",
+	StepUpsell: "https://api.dominos.com/upsell-service/stores/stepUpsellForOrder/${storeID}",
 }
 
 // Default URLs configuration (starts with USA)
@@ -98,3 +129,22 @@ var URLs = USA
 func UseInternational(config URLConfig) {
 	URLs = config
 }
+
+// language is set by UseLanguage and overrides the active configuration's
+var language string
+
+// UseLanguage sets the language of menus, orders and tracking, e.g. "fr" for
+// Quebec. It is kept when the market changes; an empty language goes back to
+// the active configuration's.
+func UseLanguage(lang string) {
+	language = lang
+}
+
+// CurrentLanguage returns the language set with UseLanguage, or the active
+// configuration's
+func CurrentLanguage() string {
+	if language != "" {
+		return language
+	}
+	return URLs.Language
+}
@@ -0,0 +1,23 @@
+package utils
+
+import "testing"
+
+func TestUseLanguageSurvivesMarketChange(t *testing.T) {
+	saved := URLs
+	t.Cleanup(func() {
+		URLs = saved
+		UseLanguage("")
+	})
+
+	UseInternational(Canada)
+	UseLanguage("fr")
+	UseInternational(USA)
+	if got := CurrentLanguage(); got != "fr" {
+		t.Errorf("CurrentLanguage() after changing market = %q, want fr", got)
+	}
+
+	UseLanguage("")
+	if got := CurrentLanguage(); got != USA.Language {
+		t.Errorf("CurrentLanguage() after clearing = %q, want %q", got, USA.Language)
+	}
+}